@@ -19,7 +19,7 @@ type Config struct {
 
 // GetCurrentBranchHandler handles the getCurrentBranch tool request.
 func GetCurrentBranchHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	branch, err := git.GetCurrentBranch()
+	branch, err := git.GetCurrentBranch("")
 	if err != nil {
 		return mcp.NewToolResultError(err.Error()), nil
 	}
@@ -29,7 +29,7 @@ func GetCurrentBranchHandler(ctx context.Context, request mcp.CallToolRequest) (
 
 // GetMergeRequestCommentsHandler handles the getMergeRequestComments tool request.
 func GetMergeRequestCommentsHandler(ctx context.Context, request mcp.CallToolRequest, config *Config) (*mcp.CallToolResult, error) {
-	branch, err := git.GetCurrentBranch()
+	branch, err := git.GetCurrentBranch("")
 	if err != nil {
 		return mcp.NewToolResultError(err.Error()), nil
 	}
@@ -37,7 +37,7 @@ func GetMergeRequestCommentsHandler(ctx context.Context, request mcp.CallToolReq
 	client := gitlab.NewClient(config.GitLabToken)
 	sourceBranch := branch
 
-	mrs, err := client.GetMergeRequestsBySourceBranch(config.ProjectID, sourceBranch)
+	mrs, err := client.GetMergeRequestsBySourceBranch(ctx, config.ProjectID, sourceBranch)
 	if err != nil {
 		return mcp.NewToolResultError(err.Error()), nil
 	}
@@ -49,7 +49,7 @@ func GetMergeRequestCommentsHandler(ctx context.Context, request mcp.CallToolReq
 	var sb strings.Builder
 	sb.WriteString(fmt.Sprintf("Found %d merge request(s):\n", len(mrs)))
 	for _, mr := range mrs {
-		comments, err := client.GetMergeRequestComments(config.ProjectID, mr.IID)
+		comments, err := client.GetMergeRequestComments(ctx, config.ProjectID, mr.IID)
 		if err != nil {
 			return mcp.NewToolResultError(err.Error()), nil
 		}
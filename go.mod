@@ -2,9 +2,10 @@ module github.com/ondratuma/gitlab-review-mcp
 
 go 1.24.3
 
+require github.com/mark3labs/mcp-go v0.30.1
+
 require (
 	github.com/google/uuid v1.6.0 // indirect
-	github.com/mark3labs/mcp-go v0.30.1 // indirect
 	github.com/spf13/cast v1.7.1 // indirect
 	github.com/yosida95/uritemplate/v3 v3.0.2 // indirect
 )
@@ -3,7 +3,11 @@ package main
 
 import (
 	"fmt"
+	"log/slog"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/ondratuma/gitlab-review-mcp/pkg/gitlabmcp"
 )
@@ -16,15 +20,79 @@ func main() {
 		os.Exit(1)
 	}
 
-	projectID := os.Getenv("GITLAB_PROJECT_ID")
-	if projectID == "" {
-		fmt.Fprintf(os.Stderr, "Error: GITLAB_PROJECT_ID environment variable is not set\n")
-		os.Exit(1)
+	// The project is detected from the git remote when not set explicitly.
+	// Further comma-separated projects are searched by branch lookups.
+	var projectIDs []string
+	for _, projectID := range strings.Split(os.Getenv("GITLAB_PROJECT_ID"), ",") {
+		if projectID = strings.TrimSpace(projectID); projectID != "" {
+			projectIDs = append(projectIDs, projectID)
+		}
+	}
+
+	config := gitlabmcp.NewDefaultConfig(gitlabToken, "")
+	if len(projectIDs) > 0 {
+		config.ProjectID = projectIDs[0]
+		config.Projects = projectIDs[1:]
+	}
+	config.Group = os.Getenv("GITLAB_GROUP")
+	if remote := os.Getenv("GITLAB_REMOTE"); remote != "" {
+		config.GitRemote = remote
+	}
+
+	// Git operations run in the working directory unless a repository is given
+	config.RepoPath = os.Getenv("GITLAB_REPO_PATH")
+
+	// Optional settings for self-managed GitLab instances
+	config.GitLabURL = os.Getenv("GITLAB_URL")
+	config.CACertPath = os.Getenv("GITLAB_CA_CERT")
+	config.ClientCertPath = os.Getenv("GITLAB_CLIENT_CERT")
+	config.ClientKeyPath = os.Getenv("GITLAB_CLIENT_KEY")
+
+	if insecure := os.Getenv("GITLAB_INSECURE_SKIP_VERIFY"); insecure != "" {
+		skipVerify, err := strconv.ParseBool(insecure)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: GITLAB_INSECURE_SKIP_VERIFY must be a boolean, got %q\n", insecure)
+			os.Exit(1)
+		}
+		config.InsecureSkipVerify = skipVerify
+	}
+
+	// Timeouts of single GitLab API requests and of whole tool calls, zero
+	// disables them
+	for name, timeout := range map[string]*time.Duration{
+		"GITLAB_REQUEST_TIMEOUT": &config.RequestTimeout,
+		"GITLAB_TOOL_TIMEOUT":    &config.ToolTimeout,
+	} {
+		value := os.Getenv(name)
+		if value == "" {
+			continue
+		}
+		duration, err := time.ParseDuration(value)
+		if err != nil || duration < 0 {
+			fmt.Fprintf(os.Stderr, "Error: %s must be a duration such as 30s or 2m, got %q\n", name, value)
+			os.Exit(1)
+		}
+		*timeout = duration
+	}
+
+	if maxItems := os.Getenv("GITLAB_MAX_LIST_ITEMS"); maxItems != "" {
+		limit, err := strconv.Atoi(maxItems)
+		if err != nil || limit < 0 {
+			fmt.Fprintf(os.Stderr, "Error: GITLAB_MAX_LIST_ITEMS must be a non-negative integer, got %q\n", maxItems)
+			os.Exit(1)
+		}
+		config.MaxListItems = limit
+	}
+
+	// Debug logs, e.g. of retries and rate limits, go to stderr as stdout
+	// carries the MCP protocol
+	if debug, _ := strconv.ParseBool(os.Getenv("GITLAB_DEBUG")); debug {
+		slog.SetDefault(slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelDebug})))
 	}
 
 	// This file serves as a simple entry point that delegates to the actual implementation
 	// in the pkg/gitlabmcp package.
-	if err := gitlabmcp.Run(gitlabToken, projectID); err != nil {
+	if err := gitlabmcp.Run(config); err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
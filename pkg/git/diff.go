@@ -0,0 +1,124 @@
+// Package git provides utilities for interacting with Git repositories.
+package git
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DiffHunk is a single hunk of a unified diff.
+type DiffHunk struct {
+	Header   string
+	OldStart int
+	OldLines int
+	NewStart int
+	NewLines int
+	Lines    []string
+}
+
+// DiffLine is a single line of a hunk with its position in both file versions.
+// OldLine is 0 for added lines and NewLine is 0 for removed lines.
+type DiffLine struct {
+	Prefix  byte
+	OldLine int
+	NewLine int
+	Text    string
+
+	// OldPos and NewPos are the running line counters of both versions, set
+	// on every line. GitLab builds line codes from them.
+	OldPos int
+	NewPos int
+}
+
+// NumberedLines returns the lines of the hunk with their old and new line numbers.
+func (h DiffHunk) NumberedLines() []DiffLine {
+	lines := make([]DiffLine, 0, len(h.Lines))
+	oldLine, newLine := h.OldStart, h.NewStart
+
+	for _, line := range h.Lines {
+		if line == "" || line[0] == '\\' {
+			// "\ No newline at end of file" markers are not part of either file
+			continue
+		}
+
+		diffLine := DiffLine{Prefix: line[0], Text: line[1:], OldPos: oldLine, NewPos: newLine}
+		switch line[0] {
+		case '+':
+			diffLine.NewLine = newLine
+			newLine++
+		case '-':
+			diffLine.OldLine = oldLine
+			oldLine++
+		default:
+			diffLine.OldLine = oldLine
+			diffLine.NewLine = newLine
+			oldLine++
+			newLine++
+		}
+		lines = append(lines, diffLine)
+	}
+
+	return lines
+}
+
+// ParseHunks splits a unified diff into its hunks.
+func ParseHunks(diff string) ([]DiffHunk, error) {
+	var hunks []DiffHunk
+
+	for _, line := range strings.Split(diff, "\n") {
+		if strings.HasPrefix(line, "@@") {
+			hunk, err := parseHunkHeader(line)
+			if err != nil {
+				return nil, err
+			}
+			hunks = append(hunks, hunk)
+			continue
+		}
+
+		// Skip file headers preceding the first hunk and the trailing newline
+		if len(hunks) == 0 || line == "" {
+			continue
+		}
+
+		current := &hunks[len(hunks)-1]
+		current.Lines = append(current.Lines, line)
+	}
+
+	return hunks, nil
+}
+
+// parseHunkHeader parses a header such as "@@ -1,4 +1,5 @@ func main() {".
+func parseHunkHeader(header string) (DiffHunk, error) {
+	hunk := DiffHunk{Header: header, OldLines: 1, NewLines: 1}
+
+	ranges, _, ok := strings.Cut(strings.TrimPrefix(header, "@@ "), " @@")
+	if !ok {
+		return hunk, fmt.Errorf("invalid hunk header: %s", header)
+	}
+
+	oldRange, newRange, ok := strings.Cut(ranges, " ")
+	if !ok || !strings.HasPrefix(oldRange, "-") || !strings.HasPrefix(newRange, "+") {
+		return hunk, fmt.Errorf("invalid hunk header: %s", header)
+	}
+
+	if err := parseHunkRange(oldRange[1:], &hunk.OldStart, &hunk.OldLines); err != nil {
+		return hunk, fmt.Errorf("invalid hunk header: %s", header)
+	}
+	if err := parseHunkRange(newRange[1:], &hunk.NewStart, &hunk.NewLines); err != nil {
+		return hunk, fmt.Errorf("invalid hunk header: %s", header)
+	}
+
+	return hunk, nil
+}
+
+// parseHunkRange parses a "start,count" range, where the count defaults to 1.
+func parseHunkRange(r string, start, count *int) error {
+	if startPart, countPart, ok := strings.Cut(r, ","); ok {
+		if _, err := fmt.Sscanf(countPart, "%d", count); err != nil {
+			return err
+		}
+		r = startPart
+	}
+	_, err := fmt.Sscanf(r, "%d", start)
+	return err
+}
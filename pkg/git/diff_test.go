@@ -0,0 +1,78 @@
+package git
+
+import (
+	"testing"
+)
+
+// TestParseHunks tests the ParseHunks function
+func TestParseHunks(t *testing.T) {
+	diff := "--- a/main.go\n+++ b/main.go\n" +
+		"@@ -1,3 +1,4 @@ package main\n" +
+		" import \"fmt\"\n" +
+		"+import \"os\"\n" +
+		" \n" +
+		" func main() {\n" +
+		"@@ -10 +11,2 @@\n" +
+		"-\tfmt.Println(\"a\")\n" +
+		"+\tfmt.Println(\"b\")\n" +
+		"+\tos.Exit(0)\n"
+
+	hunks, err := ParseHunks(diff)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(hunks) != 2 {
+		t.Fatalf("expected 2 hunks, got %d", len(hunks))
+	}
+
+	first := hunks[0]
+	if first.OldStart != 1 || first.OldLines != 3 || first.NewStart != 1 || first.NewLines != 4 {
+		t.Errorf("unexpected first hunk range: %+v", first)
+	}
+	if len(first.Lines) != 4 {
+		t.Errorf("expected 4 lines in first hunk, got %d", len(first.Lines))
+	}
+
+	second := hunks[1]
+	if second.OldStart != 10 || second.OldLines != 1 || second.NewStart != 11 || second.NewLines != 2 {
+		t.Errorf("unexpected second hunk range: %+v", second)
+	}
+	if len(second.Lines) != 3 {
+		t.Errorf("expected 3 lines in second hunk, got %d", len(second.Lines))
+	}
+}
+
+// TestParseHunksInvalidHeader tests that malformed hunk headers are rejected
+func TestParseHunksInvalidHeader(t *testing.T) {
+	if _, err := ParseHunks("@@ broken @@\n+line\n"); err == nil {
+		t.Errorf("expected error but got nil")
+	}
+}
+
+// TestNumberedLines tests the NumberedLines method
+func TestNumberedLines(t *testing.T) {
+	hunk := DiffHunk{
+		OldStart: 10,
+		NewStart: 12,
+		Lines:    []string{" a", "-b", "+c", "+d", " e", "\\ No newline at end of file"},
+	}
+
+	expected := []DiffLine{
+		{Prefix: ' ', OldLine: 10, NewLine: 12, Text: "a", OldPos: 10, NewPos: 12},
+		{Prefix: '-', OldLine: 11, Text: "b", OldPos: 11, NewPos: 13},
+		{Prefix: '+', NewLine: 13, Text: "c", OldPos: 12, NewPos: 13},
+		{Prefix: '+', NewLine: 14, Text: "d", OldPos: 12, NewPos: 14},
+		{Prefix: ' ', OldLine: 12, NewLine: 15, Text: "e", OldPos: 12, NewPos: 15},
+	}
+
+	lines := hunk.NumberedLines()
+	if len(lines) != len(expected) {
+		t.Fatalf("expected %d lines, got %d", len(expected), len(lines))
+	}
+	for i := range expected {
+		if lines[i] != expected[i] {
+			t.Errorf("line %d: expected %+v, got %+v", i, expected[i], lines[i])
+		}
+	}
+}
@@ -3,6 +3,7 @@ package git
 
 import (
 	"bytes"
+	"fmt"
 	"os/exec"
 	"strings"
 )
@@ -11,12 +12,43 @@ import (
 // It can be replaced in tests to mock the command execution.
 var execCommand = exec.Command
 
+// gitCommand creates a git command running in the repository at dir. An
+// empty dir runs it in the working directory of the process.
+func gitCommand(dir string, args ...string) *exec.Cmd {
+	cmd := execCommand("git", args...)
+	cmd.Dir = dir
+	return cmd
+}
+
 // GetCurrentBranchFunc is the function type for GetCurrentBranch
-type GetCurrentBranchFunc func() (string, error)
+type GetCurrentBranchFunc func(dir string) (string, error)
 
-// getCurrentBranchImpl is the actual implementation of GetCurrentBranch
-func getCurrentBranchImpl() (string, error) {
-	cmd := execCommand("git", "rev-parse", "--abbrev-ref", "HEAD")
+// getCurrentBranchImpl is the actual implementation of GetCurrentBranch. While
+// a rebase is in progress it returns the branch being rebased, otherwise a
+// detached HEAD results in ErrDetachedHead.
+func getCurrentBranchImpl(dir string) (string, error) {
+	state, err := GetRepositoryState(dir)
+	if err != nil {
+		return "", err
+	}
+
+	if state.Branch == "" {
+		return "", fmt.Errorf("%w at %s", ErrDetachedHead, shortSHA(state.HeadSHA))
+	}
+
+	return state.Branch, nil
+}
+
+// GetCurrentBranch is a variable that holds the getCurrentBranchImpl function.
+// It can be replaced in tests to mock the function.
+var GetCurrentBranch GetCurrentBranchFunc = getCurrentBranchImpl
+
+// GetRepositoryRootFunc is the function type for GetRepositoryRoot
+type GetRepositoryRootFunc func(dir string) (string, error)
+
+// getRepositoryRootImpl is the actual implementation of GetRepositoryRoot
+func getRepositoryRootImpl(dir string) (string, error) {
+	cmd := gitCommand(dir, "rev-parse", "--show-toplevel")
 	var out bytes.Buffer
 	cmd.Stdout = &out
 
@@ -28,6 +60,6 @@ func getCurrentBranchImpl() (string, error) {
 	return strings.TrimSpace(out.String()), nil
 }
 
-// GetCurrentBranch is a variable that holds the getCurrentBranchImpl function.
+// GetRepositoryRoot is a variable that holds the getRepositoryRootImpl function.
 // It can be replaced in tests to mock the function.
-var GetCurrentBranch GetCurrentBranchFunc = getCurrentBranchImpl
+var GetRepositoryRoot GetRepositoryRootFunc = getRepositoryRootImpl
@@ -1,8 +1,10 @@
 package git
 
 import (
+	"errors"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"testing"
 )
 
@@ -52,7 +54,7 @@ func TestGetCurrentBranch(t *testing.T) {
 			}
 
 			// Call the function
-			branch, err := GetCurrentBranch()
+			branch, err := GetCurrentBranch("")
 
 			// Check the results
 			if tc.expectError {
@@ -69,4 +71,135 @@ func TestGetCurrentBranch(t *testing.T) {
 			}
 		})
 	}
-}
\ No newline at end of file
+}
+
+// TestGetRepositoryState tests the GetRepositoryState function
+func TestGetRepositoryState(t *testing.T) {
+	// Save the original exec.Command function and restore it after the test
+	originalExecCommand := execCommand
+	defer func() { execCommand = originalExecCommand }()
+
+	const sha = "0123456789abcdef0123456789abcdef01234567"
+
+	// Test cases
+	tests := []struct {
+		name          string
+		branch        string
+		files         map[string]string
+		expected      RepositoryState
+		currentBranch string
+		expectErr     error
+	}{
+		{
+			name:          "on a branch",
+			branch:        "main",
+			expected:      RepositoryState{Branch: "main", HeadSHA: sha},
+			currentBranch: "main",
+		},
+		{
+			name:          "merge in progress",
+			branch:        "main",
+			files:         map[string]string{"MERGE_HEAD": sha},
+			expected:      RepositoryState{Branch: "main", HeadSHA: sha, Operation: OperationMerge},
+			currentBranch: "main",
+		},
+		{
+			name:          "rebase in progress",
+			files:         map[string]string{"rebase-merge/head-name": "refs/heads/feature/login\n"},
+			expected:      RepositoryState{Branch: "feature/login", HeadSHA: sha, Detached: true, Operation: OperationRebase},
+			currentBranch: "feature/login",
+		},
+		{
+			name:      "rebase of a detached HEAD",
+			files:     map[string]string{"rebase-apply/head-name": "detached HEAD\n"},
+			expected:  RepositoryState{HeadSHA: sha, Detached: true, Operation: OperationRebase},
+			expectErr: ErrDetachedHead,
+		},
+		{
+			name:      "detached HEAD",
+			expected:  RepositoryState{HeadSHA: sha, Detached: true},
+			expectErr: ErrDetachedHead,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			gitDir := t.TempDir()
+			for name, content := range tc.files {
+				path := filepath.Join(gitDir, name)
+				if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			// Mock the exec.Command function
+			execCommand = func(command string, args ...string) *exec.Cmd {
+				switch args[0] {
+				case "symbolic-ref":
+					if tc.branch == "" {
+						return exec.Command("false")
+					}
+					return exec.Command("echo", tc.branch)
+				case "rev-parse":
+					if args[1] == "--absolute-git-dir" {
+						return exec.Command("echo", gitDir)
+					}
+					return exec.Command("echo", sha)
+				}
+				t.Fatalf("unexpected command: git %v", args)
+				return nil
+			}
+
+			state, err := GetRepositoryState(gitDir)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if state != tc.expected {
+				t.Errorf("expected state %+v, got %+v", tc.expected, state)
+			}
+
+			branch, err := GetCurrentBranch(gitDir)
+			if !errors.Is(err, tc.expectErr) {
+				t.Errorf("expected error %v, got %v", tc.expectErr, err)
+			}
+			if branch != tc.currentBranch {
+				t.Errorf("expected branch %q, got %q", tc.currentBranch, branch)
+			}
+		})
+	}
+}
+
+// TestRepositoryStateString tests the RepositoryState String method
+func TestRepositoryStateString(t *testing.T) {
+	tests := []struct {
+		state    RepositoryState
+		expected string
+	}{
+		{RepositoryState{Branch: "main"}, "on branch main"},
+		{RepositoryState{Branch: "main", Operation: OperationMerge}, "on branch main, merge in progress"},
+		{RepositoryState{Branch: "feature", HeadSHA: "0123456789abcdef", Detached: true, Operation: OperationRebase}, "HEAD detached at 01234567 while working on branch feature, rebase in progress"},
+		{RepositoryState{HeadSHA: "0123456789abcdef", Detached: true}, "HEAD detached at 01234567"},
+	}
+
+	for _, tc := range tests {
+		if actual := tc.state.String(); actual != tc.expected {
+			t.Errorf("expected %q, got %q", tc.expected, actual)
+		}
+	}
+}
+
+// TestGitCommand tests that git commands run in the given repository
+func TestGitCommand(t *testing.T) {
+	cmd := gitCommand("/path/to/repo", "status")
+	if cmd.Dir != "/path/to/repo" {
+		t.Errorf("expected the command to run in %q, got %q", "/path/to/repo", cmd.Dir)
+	}
+
+	cmd = gitCommand("", "status")
+	if cmd.Dir != "" {
+		t.Errorf("expected the command to run in the process working directory, got %q", cmd.Dir)
+	}
+}
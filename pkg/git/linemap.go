@@ -0,0 +1,165 @@
+// Package git provides utilities for interacting with Git repositories.
+package git
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// LineMapping describes where a line of an older commit is in the working tree.
+type LineMapping struct {
+	// Path is the current path of the file, which differs from the original when renamed.
+	Path string
+	// Line is the current line number, 0 when Deleted is set.
+	Line int
+	// Deleted reports that the line was removed or rewritten since the commit.
+	Deleted bool
+}
+
+// DiffWorkingTreeFunc is the function type for DiffWorkingTree
+type DiffWorkingTreeFunc func(dir, commit string) (string, error)
+
+// diffWorkingTreeImpl is the actual implementation of DiffWorkingTree
+func diffWorkingTreeImpl(dir, commit string) (string, error) {
+	// Fixed prefixes and unquoted non-ASCII paths, whatever the user's config
+	cmd := gitCommand(dir, "-c", "core.quotePath=false", "diff", "--no-color", "--no-ext-diff", "--find-renames",
+		"--unified=0", "--src-prefix=a/", "--dst-prefix=b/", commit, "--")
+	var out, stderr bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &stderr
+
+	err := cmd.Run()
+	if err != nil {
+		if strings.Contains(stderr.String(), "bad revision") || strings.Contains(stderr.String(), "unknown revision") {
+			return "", fmt.Errorf("commit %s is not available locally, run git fetch", commit)
+		}
+		return "", err
+	}
+
+	return out.String(), nil
+}
+
+// DiffWorkingTree is a variable that holds the diffWorkingTreeImpl function.
+// It returns the zero-context diff between commit and the working tree.
+// It can be replaced in tests to mock the function.
+var DiffWorkingTree DiffWorkingTreeFunc = diffWorkingTreeImpl
+
+// fileDiff is the section of a diff describing a single file.
+type fileDiff struct {
+	// OldPath and NewPath are empty for added and deleted files respectively.
+	OldPath string
+	NewPath string
+	Deleted bool
+	Hunks   []DiffHunk
+}
+
+// MapLine translates a line of path as of the diff's base commit onto the
+// working tree, using the zero-context diff produced by DiffWorkingTree.
+func MapLine(diff, path string, line int) (LineMapping, error) {
+	files, err := parseFileDiffs(diff)
+	if err != nil {
+		return LineMapping{}, err
+	}
+
+	for _, file := range files {
+		if file.OldPath != path {
+			continue
+		}
+		if file.Deleted {
+			return LineMapping{Path: path, Deleted: true}, nil
+		}
+
+		mapping := LineMapping{Path: file.NewPath, Line: line}
+		for _, hunk := range file.Hunks {
+			// Pure insertions are placed after OldStart
+			if hunk.OldLines == 0 {
+				if line > hunk.OldStart {
+					mapping.Line += hunk.NewLines
+				}
+				continue
+			}
+
+			if line < hunk.OldStart {
+				return mapping, nil
+			}
+			if line < hunk.OldStart+hunk.OldLines {
+				return LineMapping{Path: mapping.Path, Deleted: true}, nil
+			}
+			mapping.Line += hunk.NewLines - hunk.OldLines
+		}
+		return mapping, nil
+	}
+
+	return LineMapping{Path: path, Line: line}, nil
+}
+
+// parseFileDiffs splits a diff into its file sections. Paths are taken from
+// the ---/+++ and rename lines, as the "diff --git" line is ambiguous for
+// paths containing " b/".
+func parseFileDiffs(diff string) ([]fileDiff, error) {
+	var sections []string
+	for _, section := range strings.Split(diff, "\ndiff --git ") {
+		if section = strings.TrimPrefix(section, "diff --git "); section != "" {
+			sections = append(sections, section)
+		}
+	}
+
+	files := make([]fileDiff, 0, len(sections))
+	for _, section := range sections {
+		var file fileDiff
+		for _, line := range strings.Split(section, "\n") {
+			// Header lines end at the first hunk
+			if strings.HasPrefix(line, "@@") {
+				break
+			}
+
+			var err error
+			switch {
+			case strings.HasPrefix(line, "rename from "):
+				file.OldPath, err = parseDiffPath(strings.TrimPrefix(line, "rename from "), "")
+			case strings.HasPrefix(line, "rename to "):
+				file.NewPath, err = parseDiffPath(strings.TrimPrefix(line, "rename to "), "")
+			case strings.HasPrefix(line, "--- "):
+				file.OldPath, err = parseDiffPath(strings.TrimPrefix(line, "--- "), "a/")
+			case strings.HasPrefix(line, "+++ "):
+				file.NewPath, err = parseDiffPath(strings.TrimPrefix(line, "+++ "), "b/")
+			case strings.HasPrefix(line, "deleted file mode"):
+				file.Deleted = true
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		hunks, err := ParseHunks(section)
+		if err != nil {
+			return nil, err
+		}
+		file.Hunks = hunks
+
+		files = append(files, file)
+	}
+
+	return files, nil
+}
+
+// parseDiffPath parses a path of a diff header, which git quotes when it
+// contains special characters and follows with a tab when it contains
+// spaces. The prefix is removed and /dev/null yields an empty path.
+func parseDiffPath(value, prefix string) (string, error) {
+	value = strings.TrimSuffix(value, "\t")
+	if strings.HasPrefix(value, `"`) {
+		unquoted, err := strconv.Unquote(value)
+		if err != nil {
+			return "", fmt.Errorf("invalid path in diff: %s", value)
+		}
+		value = unquoted
+	}
+	if value == "/dev/null" {
+		return "", nil
+	}
+
+	return strings.TrimPrefix(value, prefix), nil
+}
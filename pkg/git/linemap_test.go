@@ -0,0 +1,141 @@
+package git
+
+import (
+	"testing"
+)
+
+// TestMapLine tests the MapLine function
+func TestMapLine(t *testing.T) {
+	diff := "diff --git a/main.go b/main.go\n" +
+		"index 1111111..2222222 100644\n" +
+		"--- a/main.go\n" +
+		"+++ b/main.go\n" +
+		"@@ -3,0 +4,2 @@ package main\n" +
+		"+import \"os\"\n" +
+		"+\n" +
+		"@@ -10,2 +12 @@ func main() {\n" +
+		"-\ta()\n" +
+		"-\tb()\n" +
+		"+\tc()\n" +
+		"diff --git a/old.go b/new.go\n" +
+		"similarity index 90%\n" +
+		"rename from old.go\n" +
+		"rename to new.go\n" +
+		"--- a/old.go\n" +
+		"+++ b/new.go\n" +
+		"@@ -1 +0,0 @@\n" +
+		"-// removed header\n" +
+		"diff --git a/gone.go b/gone.go\n" +
+		"deleted file mode 100644\n" +
+		"--- a/gone.go\n" +
+		"+++ /dev/null\n" +
+		"@@ -1 +0,0 @@\n" +
+		"-package gone\n" +
+		"diff --git a/x b/y z.go b/x b/y z.go\n" +
+		"index 422c2b7..95f3767 100644\n" +
+		"--- a/x b/y z.go\t\n" +
+		"+++ b/x b/y z.go\t\n" +
+		"@@ -1,0 +2 @@ a\n" +
+		"+c\n" +
+		"diff --git \"a/we\\\"ird.go\" \"b/we\\\"ird 2.go\"\n" +
+		"similarity index 100%\n" +
+		"rename from \"we\\\"ird.go\"\n" +
+		"rename to \"we\\\"ird 2.go\"\n" +
+		"diff --git a/\u00fcn\u00ef.go b/\u00fcn\u00ef.go\n" +
+		"--- a/\u00fcn\u00ef.go\n" +
+		"+++ b/\u00fcn\u00ef.go\n" +
+		"@@ -1,0 +2,3 @@\n" +
+		"+a\n" +
+		"+b\n" +
+		"+c\n"
+
+	// Test cases
+	tests := []struct {
+		name     string
+		path     string
+		line     int
+		expected LineMapping
+	}{
+		{
+			name:     "line before any change",
+			path:     "main.go",
+			line:     2,
+			expected: LineMapping{Path: "main.go", Line: 2},
+		},
+		{
+			name:     "line at the insertion point",
+			path:     "main.go",
+			line:     3,
+			expected: LineMapping{Path: "main.go", Line: 3},
+		},
+		{
+			name:     "line shifted by an insertion",
+			path:     "main.go",
+			line:     5,
+			expected: LineMapping{Path: "main.go", Line: 7},
+		},
+		{
+			name:     "rewritten line",
+			path:     "main.go",
+			line:     11,
+			expected: LineMapping{Path: "main.go", Deleted: true},
+		},
+		{
+			name:     "line after several hunks",
+			path:     "main.go",
+			line:     20,
+			expected: LineMapping{Path: "main.go", Line: 21},
+		},
+		{
+			name:     "renamed file",
+			path:     "old.go",
+			line:     5,
+			expected: LineMapping{Path: "new.go", Line: 4},
+		},
+		{
+			name:     "deleted file",
+			path:     "gone.go",
+			line:     1,
+			expected: LineMapping{Path: "gone.go", Deleted: true},
+		},
+		{
+			name:     "path containing \" b/\" and spaces",
+			path:     "x b/y z.go",
+			line:     2,
+			expected: LineMapping{Path: "x b/y z.go", Line: 3},
+		},
+		{
+			name:     "quoted renamed path",
+			path:     "we\"ird.go",
+			line:     1,
+			expected: LineMapping{Path: "we\"ird 2.go", Line: 1},
+		},
+		{
+			name:     "non-ASCII path",
+			path:     "\u00fcn\u00ef.go",
+			line:     4,
+			expected: LineMapping{Path: "\u00fcn\u00ef.go", Line: 7},
+		},
+		{
+			name:     "unchanged file",
+			path:     "other.go",
+			line:     8,
+			expected: LineMapping{Path: "other.go", Line: 8},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			// Call the function
+			mapping, err := MapLine(diff, tc.path, tc.line)
+
+			// Check the results
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if mapping != tc.expected {
+				t.Errorf("expected %+v, got %+v", tc.expected, mapping)
+			}
+		})
+	}
+}
@@ -0,0 +1,90 @@
+// Package git provides utilities for interacting with Git repositories.
+package git
+
+import (
+	"bytes"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// RemoteURL is a parsed git remote URL.
+type RemoteURL struct {
+	Scheme string
+	Host   string
+	Port   string
+	Path   string
+}
+
+// GetRemoteURLFunc is the function type for GetRemoteURL
+type GetRemoteURLFunc func(dir, remote string) (string, error)
+
+// getRemoteURLImpl is the actual implementation of GetRemoteURL
+func getRemoteURLImpl(dir, remote string) (string, error) {
+	cmd := gitCommand(dir, "remote", "get-url", remote)
+	var out bytes.Buffer
+	cmd.Stdout = &out
+
+	err := cmd.Run()
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(out.String()), nil
+}
+
+// GetRemoteURL is a variable that holds the getRemoteURLImpl function.
+// It can be replaced in tests to mock the function.
+var GetRemoteURL GetRemoteURLFunc = getRemoteURLImpl
+
+// ParseRemoteURL parses an HTTPS, SSH or scp-style git remote URL.
+func ParseRemoteURL(raw string) (*RemoteURL, error) {
+	raw = strings.TrimSpace(raw)
+
+	var remote RemoteURL
+	if strings.Contains(raw, "://") {
+		u, err := url.Parse(raw)
+		if err != nil {
+			return nil, err
+		}
+		remote = RemoteURL{
+			Scheme: u.Scheme,
+			Host:   u.Hostname(),
+			Port:   u.Port(),
+			Path:   u.Path,
+		}
+	} else {
+		// scp-style syntax: [user@]host:path
+		host, path, ok := strings.Cut(raw, ":")
+		if !ok || strings.ContainsAny(host, "/\\") {
+			return nil, fmt.Errorf("unsupported git remote URL: %s", raw)
+		}
+		if _, after, found := strings.Cut(host, "@"); found {
+			host = after
+		}
+		remote = RemoteURL{
+			Scheme: "ssh",
+			Host:   host,
+			Path:   path,
+		}
+	}
+
+	remote.Path = strings.TrimSuffix(strings.Trim(remote.Path, "/"), ".git")
+	if remote.Host == "" || remote.Path == "" {
+		return nil, fmt.Errorf("unsupported git remote URL: %s", raw)
+	}
+
+	return &remote, nil
+}
+
+// WebURL returns the base URL of the web instance hosting the remote. SSH
+// remotes are assumed to be served over HTTPS on the default port.
+func (r RemoteURL) WebURL() string {
+	if r.Scheme != "http" && r.Scheme != "https" {
+		return "https://" + r.Host
+	}
+	if r.Port != "" {
+		return fmt.Sprintf("%s://%s:%s", r.Scheme, r.Host, r.Port)
+	}
+	return fmt.Sprintf("%s://%s", r.Scheme, r.Host)
+}
@@ -0,0 +1,160 @@
+// Package git provides utilities for interacting with Git repositories.
+package git
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ErrDetachedHead is returned by GetCurrentBranch when HEAD does not point to a branch.
+var ErrDetachedHead = errors.New("HEAD is detached")
+
+// Operations that can be in progress in a repository.
+const (
+	OperationRebase     = "rebase"
+	OperationMerge      = "merge"
+	OperationCherryPick = "cherry-pick"
+	OperationRevert     = "revert"
+	OperationBisect     = "bisect"
+)
+
+// RepositoryState describes what HEAD points to.
+type RepositoryState struct {
+	// Branch is the checked-out branch, or the branch being rebased. It is
+	// empty when HEAD is detached otherwise.
+	Branch string
+	// HeadSHA is the commit HEAD points to, empty on an unborn branch.
+	HeadSHA string
+	// Detached reports that HEAD does not point to a branch.
+	Detached bool
+	// Operation is the operation in progress, if any.
+	Operation string
+}
+
+// String describes the state for humans.
+func (s RepositoryState) String() string {
+	var sb strings.Builder
+	switch {
+	case !s.Detached:
+		sb.WriteString(fmt.Sprintf("on branch %s", s.Branch))
+	case s.Branch != "":
+		sb.WriteString(fmt.Sprintf("HEAD detached at %s while working on branch %s", shortSHA(s.HeadSHA), s.Branch))
+	default:
+		sb.WriteString(fmt.Sprintf("HEAD detached at %s", shortSHA(s.HeadSHA)))
+	}
+	if s.Operation != "" {
+		sb.WriteString(fmt.Sprintf(", %s in progress", s.Operation))
+	}
+
+	return sb.String()
+}
+
+// shortSHA abbreviates a commit SHA.
+func shortSHA(sha string) string {
+	if len(sha) > 8 {
+		return sha[:8]
+	}
+	return sha
+}
+
+// operationMarkers maps files in the git directory to the operation they indicate.
+var operationMarkers = []struct {
+	path      string
+	operation string
+}{
+	{"rebase-merge", OperationRebase},
+	{"rebase-apply", OperationRebase},
+	{"MERGE_HEAD", OperationMerge},
+	{"CHERRY_PICK_HEAD", OperationCherryPick},
+	{"REVERT_HEAD", OperationRevert},
+	{"BISECT_LOG", OperationBisect},
+}
+
+// runGit runs a git command in dir and returns its trimmed output.
+func runGit(dir string, args ...string) (string, error) {
+	cmd := gitCommand(dir, args...)
+	var out bytes.Buffer
+	cmd.Stdout = &out
+
+	err := cmd.Run()
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(out.String()), nil
+}
+
+// GetRepositoryStateFunc is the function type for GetRepositoryState
+type GetRepositoryStateFunc func(dir string) (RepositoryState, error)
+
+// getRepositoryStateImpl is the actual implementation of GetRepositoryState
+func getRepositoryStateImpl(dir string) (RepositoryState, error) {
+	// The git directory of a linked worktree holds its own HEAD and
+	// operation markers, so it is used rather than the main .git directory
+	gitDir, err := runGit(dir, "rev-parse", "--absolute-git-dir")
+	if err != nil {
+		return RepositoryState{}, err
+	}
+
+	var state RepositoryState
+	// HEAD does not resolve on an unborn branch
+	state.HeadSHA, _ = runGit(dir, "rev-parse", "--verify", "--quiet", "HEAD")
+
+	for _, marker := range operationMarkers {
+		if _, err := os.Stat(filepath.Join(gitDir, marker.path)); err == nil {
+			state.Operation = marker.operation
+			break
+		}
+	}
+
+	branch, err := runGit(dir, "symbolic-ref", "--quiet", "--short", "HEAD")
+	if err == nil && branch != "" {
+		state.Branch = branch
+		return state, nil
+	}
+
+	state.Detached = true
+	if state.Operation == OperationRebase {
+		state.Branch = rebaseHeadName(gitDir)
+	}
+
+	return state, nil
+}
+
+// rebaseHeadName returns the branch being rebased, read from the rebase
+// state in the git directory.
+func rebaseHeadName(gitDir string) string {
+	for _, dir := range []string{"rebase-merge", "rebase-apply"} {
+		content, err := os.ReadFile(filepath.Join(gitDir, dir, "head-name"))
+		if err != nil {
+			continue
+		}
+		// head-name is "detached HEAD" when rebasing a detached HEAD
+		name := strings.TrimSpace(string(content))
+		if branch, ok := strings.CutPrefix(name, "refs/heads/"); ok {
+			return branch
+		}
+	}
+
+	return ""
+}
+
+// GetRepositoryState is a variable that holds the getRepositoryStateImpl function.
+// It can be replaced in tests to mock the function.
+var GetRepositoryState GetRepositoryStateFunc = getRepositoryStateImpl
+
+// GetHeadCommitFunc is the function type for GetHeadCommit
+type GetHeadCommitFunc func(dir string) (string, error)
+
+// getHeadCommitImpl is the actual implementation of GetHeadCommit
+func getHeadCommitImpl(dir string) (string, error) {
+	return runGit(dir, "rev-parse", "--verify", "HEAD")
+}
+
+// GetHeadCommit is a variable that holds the getHeadCommitImpl function.
+// It can be replaced in tests to mock the function.
+var GetHeadCommit GetHeadCommitFunc = getHeadCommitImpl
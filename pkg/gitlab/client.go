@@ -2,13 +2,23 @@
 package gitlab
 
 import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
+	"os"
+	"strings"
+	"time"
 )
 
+// DefaultBaseURL is the API URL of gitlab.com.
+const DefaultBaseURL = "https://gitlab.com/api/v4"
+
 // HTTPClient interface for making HTTP requests
 type HTTPClient interface {
 	Do(req *http.Request) (*http.Response, error)
@@ -19,75 +29,193 @@ type Client struct {
 	BaseURL    string
 	Token      string
 	HTTPClient HTTPClient
+
+	// Timeout limits every API request including its retries, zero for no limit.
+	Timeout time.Duration
+
+	// MaxListItems caps the items returned by list methods, zero for no limit.
+	MaxListItems int
+}
+
+// TLSOptions configures the TLS connection to a GitLab instance.
+type TLSOptions struct {
+	CACertPath         string
+	ClientCertPath     string
+	ClientKeyPath      string
+	InsecureSkipVerify bool
 }
 
 // NewClient creates a new GitLab API client with the given token.
 func NewClient(token string) *Client {
 	return &Client{
-		BaseURL:    "https://gitlab.com/api/v4",
+		BaseURL:    DefaultBaseURL,
 		Token:      token,
-		HTTPClient: &http.Client{},
+		HTTPClient: &http.Client{Transport: NewRetryTransport(http.DefaultTransport)},
 	}
 }
 
-// GetMergeRequestComments retrieves comments for a specific merge request.
-func (c *Client) GetMergeRequestComments(projectID string, mrIID int) ([]MergeRequestNote, error) {
-	perPage := 100
-	page := 1
-	var allNotes []MergeRequestNote
-
-	for {
-		endpoint := fmt.Sprintf(
-			"%s/projects/%s/merge_requests/%d/notes?per_page=%d&page=%d",
-			c.BaseURL, url.PathEscape(projectID), mrIID, perPage, page,
-		)
-
-		req, err := http.NewRequest("GET", endpoint, nil)
+// NewClientWithOptions creates a GitLab API client for the instance at
+// instanceURL, connecting with the given TLS options.
+func NewClientWithOptions(instanceURL, token string, tlsOptions TLSOptions) (*Client, error) {
+	httpClient, err := NewHTTPClient(tlsOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Client{
+		BaseURL:    APIBaseURL(instanceURL),
+		Token:      token,
+		HTTPClient: httpClient,
+	}, nil
+}
+
+// APIBaseURL returns the REST API URL for a GitLab instance URL such as
+// https://gitlab.example.com. URLs already pointing at /api/v4 are kept.
+func APIBaseURL(instanceURL string) string {
+	instanceURL = strings.TrimRight(instanceURL, "/")
+	if instanceURL == "" {
+		return DefaultBaseURL
+	}
+	if strings.HasSuffix(instanceURL, "/api/v4") {
+		return instanceURL
+	}
+	return instanceURL + "/api/v4"
+}
+
+// NewHTTPClient creates an HTTP client using the given TLS options. Rate-limited
+// and transiently failing requests are retried.
+func NewHTTPClient(opts TLSOptions) (*http.Client, error) {
+	tlsConfig := &tls.Config{
+		InsecureSkipVerify: opts.InsecureSkipVerify,
+	}
+
+	if opts.CACertPath != "" {
+		pem, err := os.ReadFile(opts.CACertPath)
 		if err != nil {
-			return nil, err
+			return nil, fmt.Errorf("reading CA bundle: %w", err)
 		}
-		req.Header.Set("PRIVATE-TOKEN", c.Token)
 
-		resp, err := c.HTTPClient.Do(req)
+		pool, err := x509.SystemCertPool()
 		if err != nil {
-			return nil, err
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in CA bundle %s", opts.CACertPath)
 		}
-		defer resp.Body.Close()
+		tlsConfig.RootCAs = pool
+	}
 
-		if resp.StatusCode != http.StatusOK {
-			body, _ := io.ReadAll(resp.Body)
-			return nil, fmt.Errorf("GitLab API error: %s - %s", resp.Status, string(body))
+	if opts.ClientCertPath != "" || opts.ClientKeyPath != "" {
+		if opts.ClientCertPath == "" || opts.ClientKeyPath == "" {
+			return nil, fmt.Errorf("both a client certificate and a client key are required")
 		}
 
-		var notes []MergeRequestNote
-		if err := json.NewDecoder(resp.Body).Decode(&notes); err != nil {
-			return nil, err
+		cert, err := tls.LoadX509KeyPair(opts.ClientCertPath, opts.ClientKeyPath)
+		if err != nil {
+			return nil, fmt.Errorf("loading client certificate: %w", err)
 		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
 
-		allNotes = append(allNotes, notes...)
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
 
-		// GitLab uses `X-Next-Page` header for pagination
-		nextPage := resp.Header.Get("X-Next-Page")
-		if nextPage == "" {
-			break
-		}
-		page++
+	return &http.Client{Transport: NewRetryTransport(transport)}, nil
+}
+
+// IsGitLab reports whether the instance at BaseURL answers like GitLab,
+// without sending the token. GitLab marks API responses with the X-Gitlab-Meta
+// header and answers anonymous version requests with a JSON 401 Unauthorized.
+func (c *Client) IsGitLab(ctx context.Context) (bool, error) {
+	ctx, cancel := c.requestContext(ctx)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.BaseURL+"/version", nil)
+	if err != nil {
+		return false, err
+	}
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+
+	if resp.Header.Get("X-Gitlab-Meta") != "" {
+		return true, nil
+	}
+
+	var body struct {
+		Message string `json:"message"`
+		Version string `json:"version"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 4096)).Decode(&body); err != nil {
+		return false, nil
 	}
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return body.Version != "", nil
+	case http.StatusUnauthorized:
+		return body.Message == "401 Unauthorized", nil
+	}
+	return false, nil
+}
 
-	return allNotes, nil
+// GetMergeRequestComments retrieves comments for a specific merge request.
+func (c *Client) GetMergeRequestComments(ctx context.Context, projectID string, mrIID int) ([]MergeRequestNote, error) {
+	endpoint := fmt.Sprintf("%s/projects/%s/merge_requests/%d/notes",
+		c.BaseURL, url.PathEscape(projectID), mrIID)
+
+	return listAll[MergeRequestNote](ctx, c, endpoint)
 }
 
 // GetMergeRequestsBySourceBranch retrieves merge requests for a specific source branch.
-func (c *Client) GetMergeRequestsBySourceBranch(projectID, sourceBranch string) ([]MergeRequest, error) {
-	endpoint := fmt.Sprintf("%s/projects/%s/merge_requests?source_branch=%s", 
+func (c *Client) GetMergeRequestsBySourceBranch(ctx context.Context, projectID, sourceBranch string) ([]MergeRequest, error) {
+	endpoint := fmt.Sprintf("%s/projects/%s/merge_requests?source_branch=%s",
 		c.BaseURL, url.PathEscape(projectID), url.QueryEscape(sourceBranch))
 
-	req, err := http.NewRequest("GET", endpoint, nil)
+	// A capped lookup could miss the open merge request
+	return listUncapped[MergeRequest](ctx, c, endpoint)
+}
+
+// requestContext applies the request timeout of the client to ctx.
+func (c *Client) requestContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if c.Timeout <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, c.Timeout)
+}
+
+// doRequest sends an authenticated request to the GitLab API and decodes the
+// JSON response into out. A nil payload sends no body, a nil out discards it.
+func (c *Client) doRequest(ctx context.Context, method, endpoint string, payload, out any) error {
+	_, err := c.doRequestWithHeaders(ctx, method, endpoint, payload, out)
+	return err
+}
+
+// doRequestWithHeaders behaves like doRequest but also returns the response
+// headers, which carry the pagination information.
+func (c *Client) doRequestWithHeaders(ctx context.Context, method, endpoint string, payload, out any) (http.Header, error) {
+	var body io.Reader
+	if payload != nil {
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return nil, err
+		}
+		body = bytes.NewReader(data)
+	}
+
+	ctx, cancel := c.requestContext(ctx)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, body)
 	if err != nil {
 		return nil, err
 	}
-
 	req.Header.Set("PRIVATE-TOKEN", c.Token)
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
 
 	resp, err := c.HTTPClient.Do(req)
 	if err != nil {
@@ -95,16 +223,389 @@ func (c *Client) GetMergeRequestsBySourceBranch(projectID, sourceBranch string)
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("GitLab API error: %s - %s", resp.Status, string(body))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, newAPIError(req, resp)
+	}
+
+	if out == nil {
+		return resp.Header, nil
+	}
+
+	return resp.Header, json.NewDecoder(resp.Body).Decode(out)
+}
+
+// doRawRequest sends an authenticated GET request for a plain text resource,
+// with the extra header, and hands the response to read before closing it.
+// Unlike doRequest it leaves the status to read, which reports unsuccessful
+// responses with newAPIError.
+func (c *Client) doRawRequest(ctx context.Context, endpoint string, header http.Header, read func(req *http.Request, resp *http.Response) error) error {
+	ctx, cancel := c.requestContext(ctx)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return err
+	}
+	for key, values := range header {
+		req.Header[key] = values
 	}
+	req.Header.Set("PRIVATE-TOKEN", c.Token)
 
-	var mrs []MergeRequest
-	err = json.NewDecoder(resp.Body).Decode(&mrs)
+	resp, err := c.HTTPClient.Do(req)
 	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	return read(req, resp)
+}
+
+// ReplyToDiscussion adds a note to an existing merge request discussion thread.
+func (c *Client) ReplyToDiscussion(ctx context.Context, projectID string, mrIID int, discussionID, body string) (*MergeRequestNote, error) {
+	endpoint := fmt.Sprintf("%s/projects/%s/merge_requests/%d/discussions/%s/notes",
+		c.BaseURL, url.PathEscape(projectID), mrIID, url.PathEscape(discussionID))
+
+	var note MergeRequestNote
+	payload := map[string]string{"body": body}
+	if err := c.doRequest(ctx, http.MethodPost, endpoint, payload, &note); err != nil {
+		return nil, err
+	}
+
+	return &note, nil
+}
+
+// GetMergeRequestDiscussion retrieves a single discussion thread of a merge request.
+func (c *Client) GetMergeRequestDiscussion(ctx context.Context, projectID string, mrIID int, discussionID string) (*Discussion, error) {
+	endpoint := fmt.Sprintf("%s/projects/%s/merge_requests/%d/discussions/%s",
+		c.BaseURL, url.PathEscape(projectID), mrIID, url.PathEscape(discussionID))
+
+	var discussion Discussion
+	if err := c.doRequest(ctx, http.MethodGet, endpoint, nil, &discussion); err != nil {
+		return nil, err
+	}
+
+	return &discussion, nil
+}
+
+// ResolveDiscussion resolves or unresolves a merge request discussion thread.
+func (c *Client) ResolveDiscussion(ctx context.Context, projectID string, mrIID int, discussionID string, resolved bool) (*Discussion, error) {
+	endpoint := fmt.Sprintf("%s/projects/%s/merge_requests/%d/discussions/%s?resolved=%t",
+		c.BaseURL, url.PathEscape(projectID), mrIID, url.PathEscape(discussionID), resolved)
+
+	// Resolving twice leaves the discussion as it is
+	var discussion Discussion
+	if err := c.doRequest(WithRetry(ctx), http.MethodPut, endpoint, nil, &discussion); err != nil {
+		return nil, err
+	}
+
+	return &discussion, nil
+}
+
+// GetCurrentUser retrieves the user the API token belongs to.
+func (c *Client) GetCurrentUser(ctx context.Context) (*User, error) {
+	endpoint := fmt.Sprintf("%s/user", c.BaseURL)
+
+	var user User
+	if err := c.doRequest(ctx, http.MethodGet, endpoint, nil, &user); err != nil {
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+// GetMergeRequestDiscussions retrieves all discussion threads of a merge request.
+func (c *Client) GetMergeRequestDiscussions(ctx context.Context, projectID string, mrIID int) ([]Discussion, error) {
+	endpoint := fmt.Sprintf("%s/projects/%s/merge_requests/%d/discussions",
+		c.BaseURL, url.PathEscape(projectID), mrIID)
+
+	return listAll[Discussion](ctx, c, endpoint)
+}
+
+// GetMergeRequestDiffs retrieves the file changes of a merge request.
+func (c *Client) GetMergeRequestDiffs(ctx context.Context, projectID string, mrIID int) ([]MergeRequestDiff, error) {
+	endpoint := fmt.Sprintf("%s/projects/%s/merge_requests/%d/diffs",
+		c.BaseURL, url.PathEscape(projectID), mrIID)
+
+	return listAll[MergeRequestDiff](ctx, c, endpoint)
+}
+
+// GetMergeRequestVersions retrieves the diff versions of a merge request, newest first.
+func (c *Client) GetMergeRequestVersions(ctx context.Context, projectID string, mrIID int) ([]MergeRequestVersion, error) {
+	endpoint := fmt.Sprintf("%s/projects/%s/merge_requests/%d/versions",
+		c.BaseURL, url.PathEscape(projectID), mrIID)
+
+	return listAll[MergeRequestVersion](ctx, c, endpoint)
+}
+
+// GetMergeRequestVersion retrieves a single diff version of a merge request including its diffs.
+func (c *Client) GetMergeRequestVersion(ctx context.Context, projectID string, mrIID, versionID int) (*MergeRequestVersion, error) {
+	endpoint := fmt.Sprintf("%s/projects/%s/merge_requests/%d/versions/%d",
+		c.BaseURL, url.PathEscape(projectID), mrIID, versionID)
+
+	var version MergeRequestVersion
+	if err := c.doRequest(ctx, http.MethodGet, endpoint, nil, &version); err != nil {
+		return nil, err
+	}
+
+	return &version, nil
+}
+
+// CreateMergeRequestDiscussion starts a new discussion thread on a merge request.
+// A nil position creates a general discussion, otherwise the thread is
+// anchored to the given diff position.
+func (c *Client) CreateMergeRequestDiscussion(ctx context.Context, projectID string, mrIID int, body string, position *NotePosition) (*Discussion, error) {
+	endpoint := fmt.Sprintf("%s/projects/%s/merge_requests/%d/discussions",
+		c.BaseURL, url.PathEscape(projectID), mrIID)
+
+	payload := struct {
+		Body     string        `json:"body"`
+		Position *NotePosition `json:"position,omitempty"`
+	}{
+		Body:     body,
+		Position: position,
+	}
+
+	var discussion Discussion
+	if err := c.doRequest(ctx, http.MethodPost, endpoint, payload, &discussion); err != nil {
 		return nil, err
 	}
 
-	return mrs, nil
+	return &discussion, nil
+}
+
+// GetDraftNotes retrieves the pending draft notes of the token owner on a merge request.
+func (c *Client) GetDraftNotes(ctx context.Context, projectID string, mrIID int) ([]DraftNote, error) {
+	endpoint := fmt.Sprintf("%s/projects/%s/merge_requests/%d/draft_notes",
+		c.BaseURL, url.PathEscape(projectID), mrIID)
+
+	return listAll[DraftNote](ctx, c, endpoint)
+}
+
+// CreateDraftNote adds a draft note to the pending review of a merge request.
+func (c *Client) CreateDraftNote(ctx context.Context, projectID string, mrIID int, opts CreateDraftNoteOptions) (*DraftNote, error) {
+	endpoint := fmt.Sprintf("%s/projects/%s/merge_requests/%d/draft_notes",
+		c.BaseURL, url.PathEscape(projectID), mrIID)
+
+	var note DraftNote
+	if err := c.doRequest(ctx, http.MethodPost, endpoint, opts, &note); err != nil {
+		return nil, err
+	}
+
+	return &note, nil
+}
+
+// UpdateDraftNote replaces the text of a draft note.
+func (c *Client) UpdateDraftNote(ctx context.Context, projectID string, mrIID, draftNoteID int, body string) (*DraftNote, error) {
+	endpoint := fmt.Sprintf("%s/projects/%s/merge_requests/%d/draft_notes/%d",
+		c.BaseURL, url.PathEscape(projectID), mrIID, draftNoteID)
+
+	// Setting the same text twice leaves the draft note as it is
+	var note DraftNote
+	payload := map[string]string{"note": body}
+	if err := c.doRequest(WithRetry(ctx), http.MethodPut, endpoint, payload, &note); err != nil {
+		return nil, err
+	}
+
+	return &note, nil
+}
+
+// DeleteDraftNote deletes a draft note.
+func (c *Client) DeleteDraftNote(ctx context.Context, projectID string, mrIID, draftNoteID int) error {
+	endpoint := fmt.Sprintf("%s/projects/%s/merge_requests/%d/draft_notes/%d",
+		c.BaseURL, url.PathEscape(projectID), mrIID, draftNoteID)
+
+	return c.doRequest(ctx, http.MethodDelete, endpoint, nil, nil)
+}
+
+// PublishDraftNote publishes a single draft note.
+func (c *Client) PublishDraftNote(ctx context.Context, projectID string, mrIID, draftNoteID int) error {
+	endpoint := fmt.Sprintf("%s/projects/%s/merge_requests/%d/draft_notes/%d/publish",
+		c.BaseURL, url.PathEscape(projectID), mrIID, draftNoteID)
+
+	return c.doRequest(ctx, http.MethodPut, endpoint, nil, nil)
+}
+
+// PublishAllDraftNotes publishes all pending draft notes of the token owner at once.
+func (c *Client) PublishAllDraftNotes(ctx context.Context, projectID string, mrIID int) error {
+	endpoint := fmt.Sprintf("%s/projects/%s/merge_requests/%d/draft_notes/bulk_publish",
+		c.BaseURL, url.PathEscape(projectID), mrIID)
+
+	return c.doRequest(ctx, http.MethodPost, endpoint, nil, nil)
+}
+
+// ApplySuggestion applies a single suggestion, committing it to the source branch.
+// Suggestion IDs are global, so no project is needed. An empty commit message
+// uses the project's default.
+func (c *Client) ApplySuggestion(ctx context.Context, suggestionID int, commitMessage string) (*Suggestion, error) {
+	endpoint := fmt.Sprintf("%s/suggestions/%d/apply", c.BaseURL, suggestionID)
+
+	payload := map[string]string{}
+	if commitMessage != "" {
+		payload["commit_message"] = commitMessage
+	}
+
+	var suggestion Suggestion
+	if err := c.doRequest(ctx, http.MethodPut, endpoint, payload, &suggestion); err != nil {
+		return nil, err
+	}
+
+	return &suggestion, nil
+}
+
+// ApplySuggestions applies several suggestions in a single commit.
+// An empty commit message uses the project's default.
+func (c *Client) ApplySuggestions(ctx context.Context, suggestionIDs []int, commitMessage string) ([]Suggestion, error) {
+	endpoint := fmt.Sprintf("%s/suggestions/batch_apply", c.BaseURL)
+
+	payload := struct {
+		IDs           []int  `json:"ids"`
+		CommitMessage string `json:"commit_message,omitempty"`
+	}{
+		IDs:           suggestionIDs,
+		CommitMessage: commitMessage,
+	}
+
+	var suggestions []Suggestion
+	if err := c.doRequest(ctx, http.MethodPut, endpoint, payload, &suggestions); err != nil {
+		return nil, err
+	}
+
+	return suggestions, nil
+}
+
+// GetMergeRequest retrieves a single merge request by its IID.
+func (c *Client) GetMergeRequest(ctx context.Context, projectID string, mrIID int) (*MergeRequest, error) {
+	endpoint := fmt.Sprintf("%s/projects/%s/merge_requests/%d",
+		c.BaseURL, url.PathEscape(projectID), mrIID)
+
+	var mr MergeRequest
+	if err := c.doRequest(ctx, http.MethodGet, endpoint, nil, &mr); err != nil {
+		return nil, err
+	}
+
+	return &mr, nil
+}
+
+// GetMergeRequestsByCommit retrieves the merge requests containing a commit.
+func (c *Client) GetMergeRequestsByCommit(ctx context.Context, projectID, sha string) ([]MergeRequest, error) {
+	endpoint := fmt.Sprintf("%s/projects/%s/repository/commits/%s/merge_requests",
+		c.BaseURL, url.PathEscape(projectID), url.PathEscape(sha))
+
+	// A capped lookup could miss the open merge request
+	return listUncapped[MergeRequest](ctx, c, endpoint)
+}
+
+// GetGroupMergeRequestsBySourceBranch retrieves merge requests for a specific
+// source branch across all projects of a group.
+func (c *Client) GetGroupMergeRequestsBySourceBranch(ctx context.Context, groupID, sourceBranch string) ([]MergeRequest, error) {
+	endpoint := fmt.Sprintf("%s/groups/%s/merge_requests?source_branch=%s",
+		c.BaseURL, url.PathEscape(groupID), url.QueryEscape(sourceBranch))
+
+	// A capped lookup could miss the open merge request
+	return listUncapped[MergeRequest](ctx, c, endpoint)
+}
+
+// GetMergeRequestPipelines retrieves the pipelines of a merge request, newest first.
+func (c *Client) GetMergeRequestPipelines(ctx context.Context, projectID string, mrIID int) ([]Pipeline, error) {
+	endpoint := fmt.Sprintf("%s/projects/%s/merge_requests/%d/pipelines",
+		c.BaseURL, url.PathEscape(projectID), mrIID)
+
+	return listAll[Pipeline](ctx, c, endpoint)
+}
+
+// GetLatestMergeRequestPipeline retrieves the newest pipeline of a merge
+// request, or nil when it has none.
+func (c *Client) GetLatestMergeRequestPipeline(ctx context.Context, projectID string, mrIID int) (*Pipeline, error) {
+	endpoint := fmt.Sprintf("%s/projects/%s/merge_requests/%d/pipelines",
+		c.BaseURL, url.PathEscape(projectID), mrIID)
+
+	for pipeline, err := range Paginate[Pipeline](ctx, c, endpoint, ListOptions{MaxItems: 1}) {
+		if err != nil {
+			return nil, err
+		}
+		return &pipeline, nil
+	}
+
+	return nil, nil
+}
+
+// GetPipeline retrieves a single pipeline, including its duration.
+func (c *Client) GetPipeline(ctx context.Context, projectID string, pipelineID int) (*Pipeline, error) {
+	endpoint := fmt.Sprintf("%s/projects/%s/pipelines/%d",
+		c.BaseURL, url.PathEscape(projectID), pipelineID)
+
+	var pipeline Pipeline
+	if err := c.doRequest(ctx, http.MethodGet, endpoint, nil, &pipeline); err != nil {
+		return nil, err
+	}
+
+	return &pipeline, nil
+}
+
+// GetPipelineJobs retrieves the jobs of a pipeline. Retried jobs are only
+// included in their latest attempt.
+func (c *Client) GetPipelineJobs(ctx context.Context, projectID string, pipelineID int) ([]Job, error) {
+	endpoint := fmt.Sprintf("%s/projects/%s/pipelines/%d/jobs",
+		c.BaseURL, url.PathEscape(projectID), pipelineID)
+
+	return listAll[Job](ctx, c, endpoint)
+}
+
+// GetPipelineBridges retrieves the trigger jobs of a pipeline, which
+// GetPipelineJobs does not return.
+func (c *Client) GetPipelineBridges(ctx context.Context, projectID string, pipelineID int) ([]Bridge, error) {
+	endpoint := fmt.Sprintf("%s/projects/%s/pipelines/%d/bridges",
+		c.BaseURL, url.PathEscape(projectID), pipelineID)
+
+	return listAll[Bridge](ctx, c, endpoint)
+}
+
+// GetJob retrieves a single CI job.
+func (c *Client) GetJob(ctx context.Context, projectID string, jobID int) (*Job, error) {
+	endpoint := fmt.Sprintf("%s/projects/%s/jobs/%d",
+		c.BaseURL, url.PathEscape(projectID), jobID)
+
+	var job Job
+	if err := c.doRequest(ctx, http.MethodGet, endpoint, nil, &job); err != nil {
+		return nil, err
+	}
+
+	return &job, nil
+}
+
+// GetJobTrace retrieves the end of the raw log of a CI job, including ANSI
+// escapes and section markers. At most maxBytes of the log are kept, and
+// truncated reports that its start was cut, up to the first complete line.
+func (c *Client) GetJobTrace(ctx context.Context, projectID string, jobID, maxBytes int) (trace string, truncated bool, err error) {
+	endpoint := fmt.Sprintf("%s/projects/%s/jobs/%d/trace",
+		c.BaseURL, url.PathEscape(projectID), jobID)
+
+	tail := traceTail{max: maxBytes}
+	header := http.Header{"Range": []string{fmt.Sprintf("bytes=-%d", maxBytes)}}
+	err = c.doRawRequest(ctx, endpoint, header, func(req *http.Request, resp *http.Response) error {
+		switch {
+		case resp.StatusCode == http.StatusRequestedRangeNotSatisfiable:
+			// Empty logs cannot satisfy a range
+			return nil
+		case resp.StatusCode == http.StatusPartialContent:
+			// Partial content starts at the offset of the Content-Range
+			tail.truncated = !strings.HasPrefix(resp.Header.Get("Content-Range"), "bytes 0-")
+		case resp.StatusCode < 200 || resp.StatusCode > 299:
+			return newAPIError(req, resp)
+		}
+		// Instances ignoring the range send the whole log
+		return tail.readFrom(resp.Body)
+	})
+	if err != nil {
+		return "", false, err
+	}
+
+	data := tail.data
+	if tail.truncated {
+		if idx := bytes.IndexByte(data, '\n'); idx >= 0 {
+			data = data[idx+1:]
+		}
+	}
+
+	return string(data), tail.truncated, nil
 }
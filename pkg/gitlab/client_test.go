@@ -2,9 +2,14 @@ package gitlab
 
 import (
 	"bytes"
+	"context"
+	"errors"
+	"fmt"
 	"io"
 	"net/http"
+	"strings"
 	"testing"
+	"time"
 )
 
 // MockHTTPClient is a mock implementation of the http.Client
@@ -102,7 +107,7 @@ func TestGetMergeRequestsBySourceBranch(t *testing.T) {
 			client.HTTPClient = mockClient
 
 			// Call the method
-			mrs, err := client.GetMergeRequestsBySourceBranch(tc.projectID, tc.sourceBranch)
+			mrs, err := client.GetMergeRequestsBySourceBranch(context.Background(), tc.projectID, tc.sourceBranch)
 
 			// Check the results
 			if tc.expectError {
@@ -162,10 +167,7 @@ func TestGetMergeRequestComments(t *testing.T) {
 					System:    false,
 					CreatedAt: "2023-01-01T00:00:00Z",
 					Resolved:  false,
-					Position: &struct {
-						NewPath *string "json:\"new_path\""
-						NewLine *int    "json:\"new_line\""
-					}{
+					Position: &NotePosition{
 						NewPath: strPtr("test.go"),
 						NewLine: intPtr(10),
 					},
@@ -214,10 +216,7 @@ func TestGetMergeRequestComments(t *testing.T) {
 					System:    false,
 					CreatedAt: "2023-01-01T00:00:00Z",
 					Resolved:  false,
-					Position: &struct {
-						NewPath *string "json:\"new_path\""
-						NewLine *int    "json:\"new_line\""
-					}{
+					Position: &NotePosition{
 						NewPath: strPtr("test.go"),
 						NewLine: intPtr(10),
 					},
@@ -242,6 +241,15 @@ func TestGetMergeRequestComments(t *testing.T) {
 						t.Errorf("expected PRIVATE-TOKEN header to be 'test-token', got %s", req.Header.Get("PRIVATE-TOKEN"))
 					}
 
+					// Subsequent pages are empty and end the pagination
+					if req.URL.Query().Get("page") != "1" {
+						return &http.Response{
+							StatusCode: http.StatusOK,
+							Body:       io.NopCloser(bytes.NewBufferString(`[]`)),
+							Header:     http.Header{},
+						}, nil
+					}
+
 					// Return the mock response
 					resp := &http.Response{
 						StatusCode: tc.responseStatus,
@@ -257,7 +265,7 @@ func TestGetMergeRequestComments(t *testing.T) {
 			client.HTTPClient = mockClient
 
 			// Call the method
-			notes, err := client.GetMergeRequestComments(tc.projectID, tc.mrIID)
+			notes, err := client.GetMergeRequestComments(context.Background(), tc.projectID, tc.mrIID)
 
 			// Check the results
 			if tc.expectError {
@@ -292,4 +300,1034 @@ func strPtr(s string) *string {
 
 func intPtr(i int) *int {
 	return &i
-}
\ No newline at end of file
+}
+
+// TestReplyToDiscussion tests the ReplyToDiscussion method
+func TestReplyToDiscussion(t *testing.T) {
+	// Test cases
+	tests := []struct {
+		name           string
+		discussionID   string
+		body           string
+		responseStatus int
+		responseBody   string
+		expectedNoteID int
+		expectError    bool
+	}{
+		{
+			name:           "successful reply",
+			discussionID:   "6a9c1750b37d513a43987b574953fceb50b03ce7",
+			body:           "Fixed in abc123",
+			responseStatus: http.StatusCreated,
+			responseBody:   `{"id":42,"body":"Fixed in abc123","author":{"username":"testuser"},"system":false,"created_at":"2023-01-01T00:00:00Z"}`,
+			expectedNoteID: 42,
+			expectError:    false,
+		},
+		{
+			name:           "discussion not found",
+			discussionID:   "missing",
+			body:           "Fixed in abc123",
+			responseStatus: http.StatusNotFound,
+			responseBody:   `{"message":"404 Not found"}`,
+			expectError:    true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			// Create a mock HTTP client
+			mockClient := &MockHTTPClient{
+				DoFunc: func(req *http.Request) (*http.Response, error) {
+					// Check that the request is properly formed
+					if req.Method != "POST" {
+						t.Errorf("expected POST request, got %s", req.Method)
+					}
+
+					expectedPath := "/api/v4/projects/12345/merge_requests/1/discussions/" + tc.discussionID + "/notes"
+					if req.URL.Path != expectedPath {
+						t.Errorf("expected path %q, got %q", expectedPath, req.URL.Path)
+					}
+
+					// Check that the reply body is sent
+					payload, _ := io.ReadAll(req.Body)
+					if !bytes.Contains(payload, []byte(tc.body)) {
+						t.Errorf("expected request body to contain %q, got %s", tc.body, payload)
+					}
+
+					// Return the mock response
+					return &http.Response{
+						StatusCode: tc.responseStatus,
+						Body:       io.NopCloser(bytes.NewBufferString(tc.responseBody)),
+					}, nil
+				},
+			}
+
+			// Create a client with the mock HTTP client
+			client := NewClient("test-token")
+			client.HTTPClient = mockClient
+
+			// Call the method
+			note, err := client.ReplyToDiscussion(context.Background(), "12345", 1, tc.discussionID, tc.body)
+
+			// Check the results
+			if tc.expectError {
+				if err == nil {
+					t.Errorf("expected error but got nil")
+				}
+			} else {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if note.ID != tc.expectedNoteID {
+					t.Errorf("expected note ID %d, got %d", tc.expectedNoteID, note.ID)
+				}
+			}
+		})
+	}
+}
+
+// TestResolveDiscussion tests the ResolveDiscussion method
+func TestResolveDiscussion(t *testing.T) {
+	// Test cases
+	tests := []struct {
+		name           string
+		resolved       bool
+		responseStatus int
+		responseBody   string
+		expectedQuery  string
+		expectError    bool
+	}{
+		{
+			name:           "resolve",
+			resolved:       true,
+			responseStatus: http.StatusOK,
+			responseBody:   `{"id":"abc","individual_note":false,"notes":[{"id":1,"body":"Test comment","author":{"username":"testuser"},"resolved":true}]}`,
+			expectedQuery:  "resolved=true",
+			expectError:    false,
+		},
+		{
+			name:           "unresolve",
+			resolved:       false,
+			responseStatus: http.StatusOK,
+			responseBody:   `{"id":"abc","individual_note":false,"notes":[{"id":1,"body":"Test comment","author":{"username":"testuser"},"resolved":false}]}`,
+			expectedQuery:  "resolved=false",
+			expectError:    false,
+		},
+		{
+			name:           "API error",
+			resolved:       true,
+			responseStatus: http.StatusForbidden,
+			responseBody:   `{"message":"403 Forbidden"}`,
+			expectedQuery:  "resolved=true",
+			expectError:    true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			// Create a mock HTTP client
+			mockClient := &MockHTTPClient{
+				DoFunc: func(req *http.Request) (*http.Response, error) {
+					// Check that the request is properly formed
+					if req.Method != "PUT" {
+						t.Errorf("expected PUT request, got %s", req.Method)
+					}
+					if req.URL.RawQuery != tc.expectedQuery {
+						t.Errorf("expected query %q, got %q", tc.expectedQuery, req.URL.RawQuery)
+					}
+
+					// Return the mock response
+					return &http.Response{
+						StatusCode: tc.responseStatus,
+						Body:       io.NopCloser(bytes.NewBufferString(tc.responseBody)),
+					}, nil
+				},
+			}
+
+			// Create a client with the mock HTTP client
+			client := NewClient("test-token")
+			client.HTTPClient = mockClient
+
+			// Call the method
+			discussion, err := client.ResolveDiscussion(context.Background(), "12345", 1, "abc", tc.resolved)
+
+			// Check the results
+			if tc.expectError {
+				if err == nil {
+					t.Errorf("expected error but got nil")
+				}
+			} else {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if discussion.ID != "abc" {
+					t.Errorf("expected discussion ID %q, got %q", "abc", discussion.ID)
+				}
+				if discussion.Notes[0].Resolved != tc.resolved {
+					t.Errorf("expected resolved %t, got %t", tc.resolved, discussion.Notes[0].Resolved)
+				}
+			}
+		})
+	}
+}
+
+// TestGetMergeRequestDiscussions tests the GetMergeRequestDiscussions method
+func TestGetMergeRequestDiscussions(t *testing.T) {
+	// Test cases
+	tests := []struct {
+		name                string
+		responseStatus      int
+		responsePages       []string
+		expectedDiscussions int
+		expectError         bool
+	}{
+		{
+			name:           "successful retrieval",
+			responseStatus: http.StatusOK,
+			responsePages: []string{
+				`[{"id":"abc","individual_note":false,"notes":[{"id":1,"body":"Test comment","author":{"username":"testuser"},"resolvable":true,"resolved":false,"position":{"new_path":"test.go","new_line":10}},{"id":2,"body":"Reply","author":{"username":"other"},"resolvable":true,"resolved":false}]}]`,
+			},
+			expectedDiscussions: 1,
+			expectError:         false,
+		},
+		{
+			name:           "pagination",
+			responseStatus: http.StatusOK,
+			responsePages: []string{
+				`[{"id":"abc","individual_note":true,"notes":[{"id":1,"body":"Test comment 1","author":{"username":"testuser"}}]}]`,
+				`[{"id":"def","individual_note":true,"notes":[{"id":2,"body":"Test comment 2","author":{"username":"testuser"}}]}]`,
+			},
+			expectedDiscussions: 2,
+			expectError:         false,
+		},
+		{
+			name:           "API error",
+			responseStatus: http.StatusInternalServerError,
+			responsePages:  []string{`{"error":"Internal server error"}`},
+			expectError:    true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			// Create a mock HTTP client serving one response per page
+			mockClient := &MockHTTPClient{
+				DoFunc: func(req *http.Request) (*http.Response, error) {
+					if req.URL.Path != "/api/v4/projects/12345/merge_requests/1/discussions" {
+						t.Errorf("unexpected path %q", req.URL.Path)
+					}
+
+					page := 0
+					fmt.Sscanf(req.URL.Query().Get("page"), "%d", &page)
+					if page < 1 || page > len(tc.responsePages) {
+						t.Fatalf("unexpected page %d", page)
+					}
+
+					header := http.Header{}
+					if page < len(tc.responsePages) {
+						header.Set("X-Next-Page", fmt.Sprintf("%d", page+1))
+					}
+
+					return &http.Response{
+						StatusCode: tc.responseStatus,
+						Body:       io.NopCloser(bytes.NewBufferString(tc.responsePages[page-1])),
+						Header:     header,
+					}, nil
+				},
+			}
+
+			// Create a client with the mock HTTP client
+			client := NewClient("test-token")
+			client.HTTPClient = mockClient
+
+			// Call the method
+			discussions, err := client.GetMergeRequestDiscussions(context.Background(), "12345", 1)
+
+			// Check the results
+			if tc.expectError {
+				if err == nil {
+					t.Errorf("expected error but got nil")
+				}
+			} else {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if len(discussions) != tc.expectedDiscussions {
+					t.Errorf("expected %d discussions, got %d", tc.expectedDiscussions, len(discussions))
+				}
+			}
+		})
+	}
+}
+
+// TestDiscussionResolution tests the IsResolvable and IsResolved methods
+func TestDiscussionResolution(t *testing.T) {
+	tests := []struct {
+		name               string
+		notes              []MergeRequestNote
+		expectedResolvable bool
+		expectedResolved   bool
+	}{
+		{
+			name:               "not resolvable",
+			notes:              []MergeRequestNote{{ID: 1}},
+			expectedResolvable: false,
+			expectedResolved:   false,
+		},
+		{
+			name:               "partially resolved",
+			notes:              []MergeRequestNote{{ID: 1, Resolvable: true, Resolved: true}, {ID: 2, Resolvable: true}},
+			expectedResolvable: true,
+			expectedResolved:   false,
+		},
+		{
+			name:               "resolved",
+			notes:              []MergeRequestNote{{ID: 1, Resolvable: true, Resolved: true}, {ID: 2, Resolvable: true, Resolved: true}},
+			expectedResolvable: true,
+			expectedResolved:   true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			discussion := Discussion{ID: "abc", Notes: tc.notes}
+			if discussion.IsResolvable() != tc.expectedResolvable {
+				t.Errorf("expected resolvable %t, got %t", tc.expectedResolvable, discussion.IsResolvable())
+			}
+			if discussion.IsResolved() != tc.expectedResolved {
+				t.Errorf("expected resolved %t, got %t", tc.expectedResolved, discussion.IsResolved())
+			}
+		})
+	}
+}
+
+// TestAPIBaseURL tests the APIBaseURL function
+func TestAPIBaseURL(t *testing.T) {
+	tests := []struct {
+		instanceURL string
+		expected    string
+	}{
+		{instanceURL: "", expected: DefaultBaseURL},
+		{instanceURL: "https://gitlab.example.com", expected: "https://gitlab.example.com/api/v4"},
+		{instanceURL: "https://gitlab.example.com/", expected: "https://gitlab.example.com/api/v4"},
+		{instanceURL: "https://example.com/gitlab/api/v4", expected: "https://example.com/gitlab/api/v4"},
+	}
+
+	for _, tc := range tests {
+		if got := APIBaseURL(tc.instanceURL); got != tc.expected {
+			t.Errorf("APIBaseURL(%q): expected %q, got %q", tc.instanceURL, tc.expected, got)
+		}
+	}
+}
+
+// TestNewHTTPClient tests the NewHTTPClient function
+func TestNewHTTPClient(t *testing.T) {
+	tests := []struct {
+		name        string
+		opts        TLSOptions
+		expectError bool
+	}{
+		{
+			name:        "defaults",
+			opts:        TLSOptions{},
+			expectError: false,
+		},
+		{
+			name:        "insecure skip verify",
+			opts:        TLSOptions{InsecureSkipVerify: true},
+			expectError: false,
+		},
+		{
+			name:        "missing CA bundle",
+			opts:        TLSOptions{CACertPath: "/nonexistent/ca.pem"},
+			expectError: true,
+		},
+		{
+			name:        "client certificate without key",
+			opts:        TLSOptions{ClientCertPath: "/nonexistent/client.pem"},
+			expectError: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			client, err := NewHTTPClient(tc.opts)
+			if tc.expectError {
+				if err == nil {
+					t.Errorf("expected error but got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			transport := client.Transport.(*RetryTransport).Base.(*http.Transport)
+			if transport.TLSClientConfig.InsecureSkipVerify != tc.opts.InsecureSkipVerify {
+				t.Errorf("expected InsecureSkipVerify %t, got %t", tc.opts.InsecureSkipVerify, transport.TLSClientConfig.InsecureSkipVerify)
+			}
+		})
+	}
+}
+
+// TestGetMergeRequestDiffs tests the GetMergeRequestDiffs method
+func TestGetMergeRequestDiffs(t *testing.T) {
+	responseBody := `[{"old_path":"old.go","new_path":"new.go","a_mode":"100644","b_mode":"100644","diff":"@@ -1 +1 @@\n-a\n+b\n","new_file":false,"renamed_file":true,"deleted_file":false}]`
+
+	// Create a mock HTTP client
+	mockClient := &MockHTTPClient{
+		DoFunc: func(req *http.Request) (*http.Response, error) {
+			if req.URL.Path != "/api/v4/projects/12345/merge_requests/1/diffs" {
+				t.Errorf("unexpected path %q", req.URL.Path)
+			}
+
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(bytes.NewBufferString(responseBody)),
+				Header:     http.Header{},
+			}, nil
+		},
+	}
+
+	// Create a client with the mock HTTP client
+	client := NewClient("test-token")
+	client.HTTPClient = mockClient
+
+	// Call the method
+	diffs, err := client.GetMergeRequestDiffs(context.Background(), "12345", 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Check the results
+	if len(diffs) != 1 {
+		t.Fatalf("expected 1 diff, got %d", len(diffs))
+	}
+	if diffs[0].OldPath != "old.go" || diffs[0].NewPath != "new.go" || !diffs[0].RenamedFile {
+		t.Errorf("unexpected diff: %+v", diffs[0])
+	}
+}
+
+// TestCreateMergeRequestDiscussion tests the CreateMergeRequestDiscussion method
+func TestCreateMergeRequestDiscussion(t *testing.T) {
+	// Create a mock HTTP client
+	mockClient := &MockHTTPClient{
+		DoFunc: func(req *http.Request) (*http.Response, error) {
+			if req.Method != "POST" {
+				t.Errorf("expected POST request, got %s", req.Method)
+			}
+			if req.URL.Path != "/api/v4/projects/12345/merge_requests/1/discussions" {
+				t.Errorf("unexpected path %q", req.URL.Path)
+			}
+
+			// Check that the position is sent
+			payload, _ := io.ReadAll(req.Body)
+			for _, expected := range []string{`"body":"Needs a test"`, `"head_sha":"head"`, `"new_line":10`} {
+				if !bytes.Contains(payload, []byte(expected)) {
+					t.Errorf("expected request body to contain %s, got %s", expected, payload)
+				}
+			}
+
+			return &http.Response{
+				StatusCode: http.StatusCreated,
+				Body:       io.NopCloser(bytes.NewBufferString(`{"id":"abc","individual_note":false,"notes":[{"id":1,"body":"Needs a test"}]}`)),
+			}, nil
+		},
+	}
+
+	// Create a client with the mock HTTP client
+	client := NewClient("test-token")
+	client.HTTPClient = mockClient
+
+	// Call the method
+	position := &NotePosition{HeadSHA: "head", NewPath: strPtr("main.go"), NewLine: intPtr(10)}
+	discussion, err := client.CreateMergeRequestDiscussion(context.Background(), "12345", 1, "Needs a test", position)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if discussion.ID != "abc" {
+		t.Errorf("expected discussion ID %q, got %q", "abc", discussion.ID)
+	}
+}
+
+// TestDraftNotes tests the draft note methods
+func TestDraftNotes(t *testing.T) {
+	// Test cases
+	tests := []struct {
+		name           string
+		expectedMethod string
+		expectedPath   string
+		responseStatus int
+		responseBody   string
+		call           func(c *Client) error
+	}{
+		{
+			name:           "list",
+			expectedMethod: "GET",
+			expectedPath:   "/api/v4/projects/12345/merge_requests/1/draft_notes",
+			responseStatus: http.StatusOK,
+			responseBody:   `[{"id":7,"note":"Draft"}]`,
+			call: func(c *Client) error {
+				notes, err := c.GetDraftNotes(context.Background(), "12345", 1)
+				if err == nil && (len(notes) != 1 || notes[0].ID != 7) {
+					t.Errorf("unexpected draft notes: %+v", notes)
+				}
+				return err
+			},
+		},
+		{
+			name:           "create",
+			expectedMethod: "POST",
+			expectedPath:   "/api/v4/projects/12345/merge_requests/1/draft_notes",
+			responseStatus: http.StatusCreated,
+			responseBody:   `{"id":7,"note":"Draft","discussion_id":"abc"}`,
+			call: func(c *Client) error {
+				_, err := c.CreateDraftNote(context.Background(), "12345", 1, CreateDraftNoteOptions{Note: "Draft", InReplyToDiscussionID: "abc"})
+				return err
+			},
+		},
+		{
+			name:           "update",
+			expectedMethod: "PUT",
+			expectedPath:   "/api/v4/projects/12345/merge_requests/1/draft_notes/7",
+			responseStatus: http.StatusOK,
+			responseBody:   `{"id":7,"note":"Updated"}`,
+			call: func(c *Client) error {
+				_, err := c.UpdateDraftNote(context.Background(), "12345", 1, 7, "Updated")
+				return err
+			},
+		},
+		{
+			name:           "delete",
+			expectedMethod: "DELETE",
+			expectedPath:   "/api/v4/projects/12345/merge_requests/1/draft_notes/7",
+			responseStatus: http.StatusNoContent,
+			call: func(c *Client) error {
+				return c.DeleteDraftNote(context.Background(), "12345", 1, 7)
+			},
+		},
+		{
+			name:           "publish one",
+			expectedMethod: "PUT",
+			expectedPath:   "/api/v4/projects/12345/merge_requests/1/draft_notes/7/publish",
+			responseStatus: http.StatusNoContent,
+			call: func(c *Client) error {
+				return c.PublishDraftNote(context.Background(), "12345", 1, 7)
+			},
+		},
+		{
+			name:           "bulk publish",
+			expectedMethod: "POST",
+			expectedPath:   "/api/v4/projects/12345/merge_requests/1/draft_notes/bulk_publish",
+			responseStatus: http.StatusNoContent,
+			call: func(c *Client) error {
+				return c.PublishAllDraftNotes(context.Background(), "12345", 1)
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			// Create a mock HTTP client
+			mockClient := &MockHTTPClient{
+				DoFunc: func(req *http.Request) (*http.Response, error) {
+					if req.Method != tc.expectedMethod {
+						t.Errorf("expected %s request, got %s", tc.expectedMethod, req.Method)
+					}
+					if req.URL.Path != tc.expectedPath {
+						t.Errorf("expected path %q, got %q", tc.expectedPath, req.URL.Path)
+					}
+
+					return &http.Response{
+						StatusCode: tc.responseStatus,
+						Body:       io.NopCloser(bytes.NewBufferString(tc.responseBody)),
+					}, nil
+				},
+			}
+
+			// Create a client with the mock HTTP client
+			client := NewClient("test-token")
+			client.HTTPClient = mockClient
+
+			if err := tc.call(client); err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+// TestApplySuggestions tests the ApplySuggestion and ApplySuggestions methods
+func TestApplySuggestions(t *testing.T) {
+	// Test cases
+	tests := []struct {
+		name            string
+		expectedPath    string
+		expectedPayload string
+		responseBody    string
+		call            func(c *Client) error
+	}{
+		{
+			name:            "single suggestion",
+			expectedPath:    "/api/v4/suggestions/3/apply",
+			expectedPayload: `{"commit_message":"Apply review"}`,
+			responseBody:    `{"id":3,"applied":true}`,
+			call: func(c *Client) error {
+				_, err := c.ApplySuggestion(context.Background(), 3, "Apply review")
+				return err
+			},
+		},
+		{
+			name:            "batch",
+			expectedPath:    "/api/v4/suggestions/batch_apply",
+			expectedPayload: `{"ids":[3,4]}`,
+			responseBody:    `[{"id":3,"applied":true},{"id":4,"applied":true}]`,
+			call: func(c *Client) error {
+				_, err := c.ApplySuggestions(context.Background(), []int{3, 4}, "")
+				return err
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			// Create a mock HTTP client
+			mockClient := &MockHTTPClient{
+				DoFunc: func(req *http.Request) (*http.Response, error) {
+					if req.Method != "PUT" {
+						t.Errorf("expected PUT request, got %s", req.Method)
+					}
+					if req.URL.Path != tc.expectedPath {
+						t.Errorf("expected path %q, got %q", tc.expectedPath, req.URL.Path)
+					}
+
+					payload, _ := io.ReadAll(req.Body)
+					if string(payload) != tc.expectedPayload {
+						t.Errorf("expected payload %s, got %s", tc.expectedPayload, payload)
+					}
+
+					return &http.Response{
+						StatusCode: http.StatusOK,
+						Body:       io.NopCloser(bytes.NewBufferString(tc.responseBody)),
+					}, nil
+				},
+			}
+
+			// Create a client with the mock HTTP client
+			client := NewClient("test-token")
+			client.HTTPClient = mockClient
+
+			if err := tc.call(client); err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+// TestGetMergeRequest tests the GetMergeRequest method
+func TestGetMergeRequest(t *testing.T) {
+	// Create a mock HTTP client
+	mockClient := &MockHTTPClient{
+		DoFunc: func(req *http.Request) (*http.Response, error) {
+			if req.URL.EscapedPath() != "/api/v4/projects/group%2Fproject/merge_requests/7" {
+				t.Errorf("unexpected path %q", req.URL.EscapedPath())
+			}
+
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(bytes.NewBufferString(`{"id":70,"iid":7,"title":"Fix","state":"opened"}`)),
+			}, nil
+		},
+	}
+
+	// Create a client with the mock HTTP client
+	client := NewClient("test-token")
+	client.HTTPClient = mockClient
+
+	// Call the method
+	mr, err := client.GetMergeRequest(context.Background(), "group/project", 7)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if mr.IID != 7 || mr.Title != "Fix" {
+		t.Errorf("unexpected merge request: %+v", mr)
+	}
+}
+
+// TestGetMergeRequestsByCommit tests the GetMergeRequestsByCommit method
+func TestGetMergeRequestsByCommit(t *testing.T) {
+	// Create a mock HTTP client
+	mockClient := &MockHTTPClient{
+		DoFunc: func(req *http.Request) (*http.Response, error) {
+			if req.URL.Path != "/api/v4/projects/12345/repository/commits/abc123/merge_requests" {
+				t.Errorf("unexpected path %q", req.URL.Path)
+			}
+
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(bytes.NewBufferString(`[{"iid":7,"state":"opened"},{"iid":5,"state":"merged"}]`)),
+			}, nil
+		},
+	}
+
+	// Create a client with the mock HTTP client
+	client := NewClient("test-token")
+	client.HTTPClient = mockClient
+
+	// Call the method
+	mrs, err := client.GetMergeRequestsByCommit(context.Background(), "12345", "abc123")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(mrs) != 2 || mrs[0].IID != 7 {
+		t.Errorf("unexpected merge requests: %+v", mrs)
+	}
+}
+
+// TestGetGroupMergeRequestsBySourceBranch tests the GetGroupMergeRequestsBySourceBranch method
+func TestGetGroupMergeRequestsBySourceBranch(t *testing.T) {
+	// Create a mock HTTP client
+	mockClient := &MockHTTPClient{
+		DoFunc: func(req *http.Request) (*http.Response, error) {
+			if req.URL.EscapedPath() != "/api/v4/groups/my%2Fgroup/merge_requests" {
+				t.Errorf("unexpected path %q", req.URL.EscapedPath())
+			}
+			if req.URL.Query().Get("source_branch") != "feature" {
+				t.Errorf("unexpected query %q", req.URL.RawQuery)
+			}
+
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(bytes.NewBufferString(`[{"iid":7,"project_id":42,"references":{"full":"my/group/app!7"}}]`)),
+			}, nil
+		},
+	}
+
+	// Create a client with the mock HTTP client
+	client := NewClient("test-token")
+	client.HTTPClient = mockClient
+
+	// Call the method
+	mrs, err := client.GetGroupMergeRequestsBySourceBranch(context.Background(), "my/group", "feature")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(mrs) != 1 || mrs[0].ProjectID != 42 || mrs[0].Reference() != "my/group/app!7" {
+		t.Errorf("unexpected merge requests: %+v", mrs)
+	}
+}
+
+// TestIsGitLab tests the IsGitLab method
+func TestIsGitLab(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   int
+		header   http.Header
+		body     string
+		expected bool
+	}{
+		{
+			name:     "meta header",
+			status:   http.StatusUnauthorized,
+			header:   http.Header{"X-Gitlab-Meta": []string{`{"correlation_id":"abc","version":"1"}`}},
+			body:     `{"message":"401 Unauthorized"}`,
+			expected: true,
+		},
+		{
+			name:     "anonymous request rejected",
+			status:   http.StatusUnauthorized,
+			body:     `{"message":"401 Unauthorized"}`,
+			expected: true,
+		},
+		{
+			name:     "version",
+			status:   http.StatusOK,
+			body:     `{"version":"17.5.0","revision":"abc"}`,
+			expected: true,
+		},
+		{
+			name:   "other forge",
+			status: http.StatusNotFound,
+			body:   `<!DOCTYPE html><title>Page not found</title>`,
+		},
+		{
+			name:   "other API",
+			status: http.StatusUnauthorized,
+			body:   `{"message":"Requires authentication"}`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			mockClient := &MockHTTPClient{
+				DoFunc: func(req *http.Request) (*http.Response, error) {
+					if req.URL.String() != "https://git.example.com/api/v4/version" {
+						t.Errorf("unexpected URL %q", req.URL)
+					}
+					if req.Header.Get("PRIVATE-TOKEN") != "" {
+						t.Error("expected the token not to be sent")
+					}
+
+					header := tc.header
+					if header == nil {
+						header = http.Header{}
+					}
+					return &http.Response{
+						StatusCode: tc.status,
+						Body:       io.NopCloser(bytes.NewBufferString(tc.body)),
+						Header:     header,
+					}, nil
+				},
+			}
+
+			client, err := NewClientWithOptions("https://git.example.com", "test-token", TLSOptions{})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			client.HTTPClient = mockClient
+
+			isGitLab, err := client.IsGitLab(context.Background())
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if isGitLab != tc.expected {
+				t.Errorf("expected %t, got %t", tc.expected, isGitLab)
+			}
+		})
+	}
+}
+
+// TestPipelines tests the pipeline and job methods
+func TestPipelines(t *testing.T) {
+	// Create a mock HTTP client
+	mockClient := &MockHTTPClient{
+		DoFunc: func(req *http.Request) (*http.Response, error) {
+			var body string
+			switch req.URL.Path {
+			case "/api/v4/projects/12345/merge_requests/1/pipelines":
+				body = `[{"id":101,"status":"failed"},{"id":100,"status":"success"}]`
+			case "/api/v4/projects/12345/pipelines/101":
+				body = `{"id":101,"status":"failed","duration":42}`
+			case "/api/v4/projects/12345/pipelines/101/jobs":
+				body = `[{"id":5,"name":"test","stage":"test","status":"failed","failure_reason":"script_failure","duration":1.5}]`
+			case "/api/v4/projects/12345/pipelines/101/bridges":
+				body = `[{"id":6,"name":"e2e","stage":"test","status":"failed","downstream_pipeline":{"id":200,"project_id":999,"status":"failed"}}]`
+			default:
+				t.Errorf("unexpected path %q", req.URL.Path)
+			}
+
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(bytes.NewBufferString(body)),
+				Header:     http.Header{},
+			}, nil
+		},
+	}
+
+	// Create a client with the mock HTTP client
+	client := NewClient("test-token")
+	client.HTTPClient = mockClient
+
+	pipelines, err := client.GetMergeRequestPipelines(context.Background(), "12345", 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(pipelines) != 2 || pipelines[0].ID != 101 {
+		t.Errorf("unexpected pipelines: %+v", pipelines)
+	}
+
+	latest, err := client.GetLatestMergeRequestPipeline(context.Background(), "12345", 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if latest == nil || latest.ID != 101 {
+		t.Errorf("unexpected latest pipeline: %+v", latest)
+	}
+
+	pipeline, err := client.GetPipeline(context.Background(), "12345", 101)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if pipeline.Duration == nil || *pipeline.Duration != 42 {
+		t.Errorf("unexpected pipeline: %+v", pipeline)
+	}
+
+	jobs, err := client.GetPipelineJobs(context.Background(), "12345", 101)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(jobs) != 1 || jobs[0].FailureReason != "script_failure" || jobs[0].Duration == nil || *jobs[0].Duration != 1.5 {
+		t.Errorf("unexpected jobs: %+v", jobs)
+	}
+
+	bridges, err := client.GetPipelineBridges(context.Background(), "12345", 101)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(bridges) != 1 || bridges[0].Name != "e2e" || bridges[0].DownstreamPipeline == nil || bridges[0].DownstreamPipeline.ProjectID != 999 {
+		t.Errorf("unexpected bridges: %+v", bridges)
+	}
+}
+
+// TestGetJobTrace tests the GetJobTrace method
+func TestGetJobTrace(t *testing.T) {
+	tests := []struct {
+		name              string
+		status            int
+		contentRange      string
+		body              string
+		expectedTrace     string
+		expectedTruncated bool
+		expectError       bool
+	}{
+		{
+			name:          "whole log",
+			status:        http.StatusOK,
+			body:          "\x1b[32;1mok\x1b[0;m\n",
+			expectedTrace: "\x1b[32;1mok\x1b[0;m\n",
+		},
+		{
+			name:          "range covering the log",
+			status:        http.StatusPartialContent,
+			contentRange:  "bytes 0-2/3",
+			body:          "ok\n",
+			expectedTrace: "ok\n",
+		},
+		{
+			name:              "range of the end",
+			status:            http.StatusPartialContent,
+			contentRange:      "bytes 90-109/110",
+			body:              "rst line\nlast line\n",
+			expectedTrace:     "last line\n",
+			expectedTruncated: true,
+		},
+		{
+			name:              "range ignored",
+			status:            http.StatusOK,
+			body:              strings.Repeat("early line\n", 100000) + "last line\n",
+			expectedTrace:     "last line\n",
+			expectedTruncated: true,
+		},
+		{
+			name:   "empty log",
+			status: http.StatusRequestedRangeNotSatisfiable,
+		},
+		{
+			name:        "unknown job",
+			status:      http.StatusNotFound,
+			body:        `{"message":"404 Not found"}`,
+			expectError: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			// Create a mock HTTP client
+			mockClient := &MockHTTPClient{
+				DoFunc: func(req *http.Request) (*http.Response, error) {
+					if req.URL.Path != "/api/v4/projects/12345/jobs/5/trace" {
+						t.Errorf("unexpected path %q", req.URL.Path)
+					}
+					if req.Header.Get("Range") != "bytes=-20" {
+						t.Errorf("unexpected range %q", req.Header.Get("Range"))
+					}
+
+					return &http.Response{
+						StatusCode: tc.status,
+						Body:       io.NopCloser(bytes.NewBufferString(tc.body)),
+						Header:     http.Header{"Content-Range": []string{tc.contentRange}},
+					}, nil
+				},
+			}
+
+			// Create a client with the mock HTTP client
+			client := NewClient("test-token")
+			client.HTTPClient = mockClient
+
+			trace, truncated, err := client.GetJobTrace(context.Background(), "12345", 5, 20)
+			if tc.expectError {
+				if !errors.Is(err, ErrNotFound) {
+					t.Errorf("expected a not found error, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if trace != tc.expectedTrace || truncated != tc.expectedTruncated {
+				t.Errorf("expected %q (truncated %t), got %q (truncated %t)", tc.expectedTrace, tc.expectedTruncated, trace, truncated)
+			}
+		})
+	}
+}
+
+// TestRequestContext tests that requests carry the caller's context and the client timeout
+func TestRequestContext(t *testing.T) {
+	type contextKey struct{}
+
+	tests := []struct {
+		name           string
+		timeout        time.Duration
+		cancel         bool
+		expectDeadline bool
+		expectError    bool
+	}{
+		{
+			name: "no timeout",
+		},
+		{
+			name:           "timeout",
+			timeout:        time.Minute,
+			expectDeadline: true,
+		},
+		{
+			name:        "cancelled",
+			cancel:      true,
+			expectError: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			// Create a mock HTTP client failing like http.Client on ended contexts
+			mockClient := &MockHTTPClient{
+				DoFunc: func(req *http.Request) (*http.Response, error) {
+					if req.Context().Value(contextKey{}) != "caller" {
+						t.Error("request does not carry the caller's context")
+					}
+					if _, ok := req.Context().Deadline(); ok != tc.expectDeadline {
+						t.Errorf("expected deadline %v, got %v", tc.expectDeadline, ok)
+					}
+					if err := req.Context().Err(); err != nil {
+						return nil, err
+					}
+
+					return &http.Response{
+						StatusCode: http.StatusOK,
+						Body:       io.NopCloser(bytes.NewBufferString(`{"iid":1}`)),
+					}, nil
+				},
+			}
+
+			// Create a client with the mock HTTP client
+			client := NewClient("test-token")
+			client.HTTPClient = mockClient
+			client.Timeout = tc.timeout
+
+			ctx, cancel := context.WithCancel(context.WithValue(context.Background(), contextKey{}, "caller"))
+			defer cancel()
+			if tc.cancel {
+				cancel()
+			}
+
+			// Call the method
+			_, err := client.GetMergeRequest(ctx, "12345", 1)
+			if tc.expectError {
+				if !errors.Is(err, context.Canceled) {
+					t.Errorf("expected context.Canceled, got %v", err)
+				}
+			} else if err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,153 @@
+// Package gitlab provides utilities for interacting with the GitLab API.
+package gitlab
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// Errors an APIError matches with errors.Is, by status code.
+var (
+	ErrBadRequest   = errors.New("bad request")
+	ErrUnauthorized = errors.New("unauthorized")
+	ErrForbidden    = errors.New("forbidden")
+	ErrNotFound     = errors.New("not found")
+	ErrConflict     = errors.New("conflict")
+	ErrRateLimited  = errors.New("rate limited")
+	ErrServer       = errors.New("server error")
+)
+
+// maxErrorBodyLength is the length error bodies without a message are cut to.
+const maxErrorBodyLength = 500
+
+// APIError is an unsuccessful response of the GitLab API.
+type APIError struct {
+	StatusCode int
+	// Status is the status line, e.g. "404 Not Found".
+	Status string
+
+	Method string
+	// URL is the request URL with any credentials redacted.
+	URL string
+
+	// Message is the message or error field of the response, e.g.
+	// "404 Project Not Found", or the start of the body when it has neither.
+	Message string
+	// Scope is the scope an access token lacks, as reported by
+	// insufficient_scope errors, e.g. "api read_api".
+	Scope string
+
+	// RequestID is the X-Request-Id header, which identifies the request in
+	// the logs of the instance.
+	RequestID string
+}
+
+// Error implements error.
+func (e *APIError) Error() string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("GitLab API error: %s", e.Status))
+	if e.Message != "" {
+		sb.WriteString(" - " + e.Message)
+	}
+	sb.WriteString(fmt.Sprintf(" (%s %s", e.Method, e.URL))
+	if e.RequestID != "" {
+		sb.WriteString(", request ID " + e.RequestID)
+	}
+	sb.WriteString(")")
+
+	return sb.String()
+}
+
+// Is reports whether the status code of the error corresponds to target, so
+// callers can use errors.Is(err, ErrNotFound).
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ErrBadRequest:
+		return e.StatusCode == http.StatusBadRequest
+	case ErrUnauthorized:
+		return e.StatusCode == http.StatusUnauthorized
+	case ErrForbidden:
+		return e.StatusCode == http.StatusForbidden
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound
+	case ErrConflict:
+		return e.StatusCode == http.StatusConflict
+	case ErrRateLimited:
+		return e.StatusCode == http.StatusTooManyRequests
+	case ErrServer:
+		return e.StatusCode >= 500
+	}
+	return false
+}
+
+// errorBody holds the fields GitLab uses to describe errors. The message is
+// a string, or an object of field errors for validation failures.
+type errorBody struct {
+	Message          json.RawMessage `json:"message"`
+	Error            string          `json:"error"`
+	ErrorDescription string          `json:"error_description"`
+	Scope            string          `json:"scope"`
+}
+
+// newAPIError reads an unsuccessful response to req into an APIError.
+func newAPIError(req *http.Request, resp *http.Response) *APIError {
+	apiErr := &APIError{
+		StatusCode: resp.StatusCode,
+		Status:     resp.Status,
+		Method:     req.Method,
+		URL:        req.URL.Redacted(),
+		RequestID:  resp.Header.Get("X-Request-Id"),
+	}
+	if apiErr.Status == "" {
+		apiErr.Status = fmt.Sprintf("%d %s", resp.StatusCode, http.StatusText(resp.StatusCode))
+	}
+
+	body, _ := io.ReadAll(resp.Body)
+
+	var parsed errorBody
+	if err := json.Unmarshal(body, &parsed); err == nil {
+		apiErr.Message = parseErrorMessage(parsed.Message)
+		if apiErr.Message == "" {
+			apiErr.Message = parsed.ErrorDescription
+		}
+		if apiErr.Message == "" {
+			apiErr.Message = parsed.Error
+		}
+		apiErr.Scope = parsed.Scope
+	}
+
+	// Bodies of proxies and the like are kept short
+	if apiErr.Message == "" {
+		message := strings.TrimSpace(string(body))
+		if len(message) > maxErrorBodyLength {
+			message = message[:maxErrorBodyLength] + "…"
+		}
+		apiErr.Message = message
+	}
+
+	return apiErr
+}
+
+// parseErrorMessage formats the message field of an error, a string or an
+// object such as {"title":["can't be blank"]}.
+func parseErrorMessage(raw json.RawMessage) string {
+	if len(raw) == 0 || string(raw) == "null" {
+		return ""
+	}
+
+	var message string
+	if err := json.Unmarshal(raw, &message); err == nil {
+		return message
+	}
+
+	var compact bytes.Buffer
+	if err := json.Compact(&compact, raw); err != nil {
+		return string(raw)
+	}
+	return compact.String()
+}
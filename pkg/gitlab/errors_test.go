@@ -0,0 +1,110 @@
+package gitlab
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"testing"
+)
+
+// TestAPIError tests that unsuccessful responses are returned as APIError
+func TestAPIError(t *testing.T) {
+	tests := []struct {
+		name            string
+		status          int
+		body            string
+		expectedTarget  error
+		expectedMessage string
+		expectedScope   string
+	}{
+		{
+			name:            "not found",
+			status:          http.StatusNotFound,
+			body:            `{"message":"404 Project Not Found"}`,
+			expectedTarget:  ErrNotFound,
+			expectedMessage: "404 Project Not Found",
+		},
+		{
+			name:            "unauthorized",
+			status:          http.StatusUnauthorized,
+			body:            `{"message":"401 Unauthorized"}`,
+			expectedTarget:  ErrUnauthorized,
+			expectedMessage: "401 Unauthorized",
+		},
+		{
+			name:            "insufficient scope",
+			status:          http.StatusForbidden,
+			body:            `{"error":"insufficient_scope","error_description":"The request requires higher privileges than provided by the access token.","scope":"api read_api"}`,
+			expectedTarget:  ErrForbidden,
+			expectedMessage: "The request requires higher privileges than provided by the access token.",
+			expectedScope:   "api read_api",
+		},
+		{
+			name:            "validation errors",
+			status:          http.StatusBadRequest,
+			body:            `{"message":{"note":["can't be blank"]}}`,
+			expectedTarget:  ErrBadRequest,
+			expectedMessage: `{"note":["can't be blank"]}`,
+		},
+		{
+			name:            "plain text body",
+			status:          http.StatusBadGateway,
+			body:            "Bad gateway\n",
+			expectedTarget:  ErrServer,
+			expectedMessage: "Bad gateway",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			// Create a mock HTTP client
+			mockClient := &MockHTTPClient{
+				DoFunc: func(req *http.Request) (*http.Response, error) {
+					return &http.Response{
+						StatusCode: tc.status,
+						Status:     fmt.Sprintf("%d %s", tc.status, http.StatusText(tc.status)),
+						Header:     http.Header{"X-Request-Id": []string{"req-1"}},
+						Body:       io.NopCloser(bytes.NewBufferString(tc.body)),
+					}, nil
+				},
+			}
+
+			// Create a client with the mock HTTP client
+			client := NewClient("test-token")
+			client.HTTPClient = mockClient
+
+			// Call the method
+			_, err := client.GetMergeRequest(context.Background(), "12345", 1)
+
+			if !errors.Is(err, tc.expectedTarget) {
+				t.Errorf("expected %v, got %v", tc.expectedTarget, err)
+			}
+			if errors.Is(err, ErrConflict) {
+				t.Errorf("unexpected match of ErrConflict: %v", err)
+			}
+
+			var apiErr *APIError
+			if !errors.As(err, &apiErr) {
+				t.Fatalf("expected an APIError, got %T", err)
+			}
+			if apiErr.StatusCode != tc.status {
+				t.Errorf("expected status %d, got %d", tc.status, apiErr.StatusCode)
+			}
+			if apiErr.Message != tc.expectedMessage {
+				t.Errorf("expected message %q, got %q", tc.expectedMessage, apiErr.Message)
+			}
+			if apiErr.Scope != tc.expectedScope {
+				t.Errorf("expected scope %q, got %q", tc.expectedScope, apiErr.Scope)
+			}
+			if apiErr.RequestID != "req-1" {
+				t.Errorf("expected request ID req-1, got %q", apiErr.RequestID)
+			}
+			if apiErr.Method != http.MethodGet || apiErr.URL != "https://gitlab.com/api/v4/projects/12345/merge_requests/1" {
+				t.Errorf("unexpected request %s %s", apiErr.Method, apiErr.URL)
+			}
+		})
+	}
+}
@@ -0,0 +1,244 @@
+// Package gitlab provides utilities for interacting with the GitLab API.
+package gitlab
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefaultPerPage is the page size of list requests, the maximum GitLab allows.
+const DefaultPerPage = 100
+
+// ListOptions configures the pagination of a list request.
+type ListOptions struct {
+	// PerPage is the number of items per page, DefaultPerPage when zero.
+	PerPage int
+	// MaxItems stops the iteration after this many items, zero for no limit.
+	MaxItems int
+}
+
+// Paginate iterates over the items of a list endpoint, requesting pages as
+// they are consumed. Offset pagination follows the X-Next-Page header, keyset
+// pagination, which endpoints enable with pagination=keyset, the next link of
+// the Link header. The iteration ends after the first error.
+func Paginate[T any](ctx context.Context, c *Client, endpoint string, opts ListOptions) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		var zero T
+
+		perPage := opts.PerPage
+		if perPage <= 0 {
+			perPage = DefaultPerPage
+		}
+		// Do not fetch more than needed
+		if opts.MaxItems > 0 && opts.MaxItems < perPage {
+			perPage = opts.MaxItems
+		}
+
+		next, err := firstPage(endpoint, perPage)
+		if err != nil {
+			yield(zero, err)
+			return
+		}
+
+		count := 0
+		for next != "" {
+			var items []T
+			header, err := c.doRequestWithHeaders(ctx, http.MethodGet, next, nil, &items)
+			if err != nil {
+				yield(zero, err)
+				return
+			}
+
+			for _, item := range items {
+				if !yield(item, nil) {
+					return
+				}
+				count++
+				if opts.MaxItems > 0 && count >= opts.MaxItems {
+					return
+				}
+			}
+
+			next, err = nextPage(next, header)
+			if err != nil {
+				yield(zero, err)
+				return
+			}
+		}
+	}
+}
+
+// collect gathers the items of a paginated list.
+func collect[T any](seq iter.Seq2[T, error]) ([]T, error) {
+	var items []T
+	for item, err := range seq {
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// listAll retrieves the items of a list endpoint, up to the MaxListItems of
+// the client. Capped lists are reported to the ListCapReport of ctx.
+func listAll[T any](ctx context.Context, c *Client, endpoint string) ([]T, error) {
+	if c.MaxListItems <= 0 {
+		return listUncapped[T](ctx, c, endpoint)
+	}
+
+	// One more item tells whether the list was cut
+	items, err := collect(Paginate[T](ctx, c, endpoint, ListOptions{MaxItems: c.MaxListItems + 1}))
+	if err != nil {
+		return nil, err
+	}
+	if len(items) > c.MaxListItems {
+		if report, ok := ctx.Value(listCapKey{}).(*ListCapReport); ok {
+			report.add(endpoint)
+		}
+		items = items[:c.MaxListItems]
+	}
+
+	return items, nil
+}
+
+// listUncapped retrieves all items of a list endpoint, ignoring MaxListItems,
+// for lookups that must not miss an item.
+func listUncapped[T any](ctx context.Context, c *Client, endpoint string) ([]T, error) {
+	return collect(Paginate[T](ctx, c, endpoint, ListOptions{}))
+}
+
+// listCapKey is the context key of WithListCapReport.
+type listCapKey struct{}
+
+// ListCapReport collects the lists cut short by the MaxListItems of a client.
+type ListCapReport struct {
+	mu    sync.Mutex
+	lists []string
+}
+
+// WithListCapReport returns a context whose capped lists are reported to the
+// returned report, so callers can tell that items are missing.
+func WithListCapReport(ctx context.Context) (context.Context, *ListCapReport) {
+	report := &ListCapReport{}
+	return context.WithValue(ctx, listCapKey{}, report), report
+}
+
+// add records a capped list by the API path of its endpoint.
+func (r *ListCapReport) add(endpoint string) {
+	list := endpoint
+	if u, err := url.Parse(endpoint); err == nil {
+		_, path, found := strings.Cut(u.Path, "/api/v4/")
+		if !found {
+			path = u.Path
+		}
+		list = path
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !slices.Contains(r.lists, list) {
+		r.lists = append(r.lists, list)
+	}
+}
+
+// Capped returns the API paths of the capped lists, such as
+// projects/123/merge_requests/7/discussions.
+func (r *ListCapReport) Capped() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return slices.Clone(r.lists)
+}
+
+// firstPage returns the URL of the first page of endpoint.
+func firstPage(endpoint string, perPage int) (string, error) {
+	first, err := setQuery(endpoint, "per_page", strconv.Itoa(perPage), false)
+	if err != nil {
+		return "", err
+	}
+
+	// Keyset pagination starts without a page number
+	if strings.Contains(first, "pagination=keyset") {
+		return first, nil
+	}
+	return setQuery(first, "page", "1", false)
+}
+
+// nextPage returns the URL of the page after current, or an empty string on
+// the last page. Offset pages are followed by X-Next-Page, whose Link headers
+// may point to the internal host of an instance behind a proxy. The Link
+// header is only used for keyset pagination.
+func nextPage(current string, header http.Header) (string, error) {
+	if len(header.Values("X-Next-Page")) > 0 {
+		page := header.Get("X-Next-Page")
+		if page == "" {
+			return "", nil
+		}
+		return setQuery(current, "page", page, true)
+	}
+
+	if !strings.Contains(current, "pagination=keyset") {
+		return "", nil
+	}
+	link := nextLink(header.Get("Link"))
+	if link == "" {
+		return "", nil
+	}
+
+	base, err := url.Parse(current)
+	if err != nil {
+		return "", err
+	}
+	next, err := base.Parse(link)
+	if err != nil {
+		return "", fmt.Errorf("invalid next page link %q: %w", link, err)
+	}
+	// The token is sent along, so links must not leave the instance
+	if next.Scheme != base.Scheme || next.Host != base.Host {
+		return "", fmt.Errorf("next page link %q points to another host", link)
+	}
+	return next.String(), nil
+}
+
+// nextLink extracts the rel="next" URL from a Link header such as
+// `<https://gitlab.example.com/api/v4/projects?id_after=42>; rel="next"`.
+func nextLink(header string) string {
+	for _, link := range strings.Split(header, ",") {
+		parts := strings.Split(link, ";")
+		target := strings.TrimSpace(parts[0])
+		if !strings.HasPrefix(target, "<") || !strings.HasSuffix(target, ">") {
+			continue
+		}
+		for _, param := range parts[1:] {
+			if strings.TrimSpace(param) == `rel="next"` {
+				return strings.Trim(target, "<>")
+			}
+		}
+	}
+	return ""
+}
+
+// setQuery sets a query parameter of endpoint, keeping an existing value
+// unless override is set.
+func setQuery(endpoint, key, value string, override bool) (string, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return "", err
+	}
+
+	query := u.Query()
+	if query.Has(key) && !override {
+		return endpoint, nil
+	}
+	query.Set(key, value)
+	u.RawQuery = query.Encode()
+
+	return u.String(), nil
+}
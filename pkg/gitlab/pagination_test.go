@@ -0,0 +1,288 @@
+package gitlab
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"testing"
+)
+
+// pagedItem is the item type of the pagination tests
+type pagedItem struct {
+	ID int `json:"id"`
+}
+
+// newPagedClient returns a client serving items 1 to total, perPage at a time.
+// Offset pages announce the next page with X-Next-Page, keyset pages with a
+// Link header relative to the last item.
+func newPagedClient(t *testing.T, total int, requests *[]string) *Client {
+	mockClient := &MockHTTPClient{
+		DoFunc: func(req *http.Request) (*http.Response, error) {
+			*requests = append(*requests, req.URL.RequestURI())
+
+			query := req.URL.Query()
+			perPage := 0
+			fmt.Sscanf(query.Get("per_page"), "%d", &perPage)
+			if perPage < 1 {
+				t.Fatalf("missing per_page in %s", req.URL)
+			}
+
+			var first int
+			keyset := query.Get("pagination") == "keyset"
+			if keyset {
+				fmt.Sscanf(query.Get("id_after"), "%d", &first)
+				first++
+			} else {
+				page := 0
+				fmt.Sscanf(query.Get("page"), "%d", &page)
+				first = (page-1)*perPage + 1
+			}
+
+			var body bytes.Buffer
+			body.WriteString("[")
+			last := min(first+perPage-1, total)
+			for id := first; id <= last; id++ {
+				if id > first {
+					body.WriteString(",")
+				}
+				fmt.Fprintf(&body, `{"id":%d}`, id)
+			}
+			body.WriteString("]")
+
+			header := http.Header{}
+			if last < total {
+				if keyset {
+					header.Set("Link", fmt.Sprintf(`<https://gitlab.com/api/v4/items?id_after=%d&pagination=keyset&per_page=%d>; rel="next", <https://gitlab.com/api/v4/items?pagination=keyset>; rel="first"`, last, perPage))
+				} else {
+					header.Set("X-Next-Page", fmt.Sprintf("%d", (last/perPage)+1))
+				}
+			}
+
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(&body),
+				Header:     header,
+			}, nil
+		},
+	}
+
+	client := NewClient("test-token")
+	client.HTTPClient = mockClient
+	return client
+}
+
+// TestPaginate tests the Paginate function
+func TestPaginate(t *testing.T) {
+	tests := []struct {
+		name             string
+		endpoint         string
+		total            int
+		opts             ListOptions
+		expectedItems    int
+		expectedRequests int
+	}{
+		{
+			name:             "offset pagination",
+			endpoint:         "https://gitlab.com/api/v4/items",
+			total:            250,
+			expectedItems:    250,
+			expectedRequests: 3,
+		},
+		{
+			name:             "keyset pagination",
+			endpoint:         "https://gitlab.com/api/v4/items?pagination=keyset",
+			total:            25,
+			opts:             ListOptions{PerPage: 10},
+			expectedItems:    25,
+			expectedRequests: 3,
+		},
+		{
+			name:             "max items stops early",
+			endpoint:         "https://gitlab.com/api/v4/items",
+			total:            250,
+			opts:             ListOptions{PerPage: 20, MaxItems: 30},
+			expectedItems:    30,
+			expectedRequests: 2,
+		},
+		{
+			name:             "small max items shrinks the page",
+			endpoint:         "https://gitlab.com/api/v4/items",
+			total:            250,
+			opts:             ListOptions{MaxItems: 5},
+			expectedItems:    5,
+			expectedRequests: 1,
+		},
+		{
+			name:             "empty list",
+			endpoint:         "https://gitlab.com/api/v4/items",
+			expectedRequests: 1,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var requests []string
+			client := newPagedClient(t, tc.total, &requests)
+
+			items, err := collect(Paginate[pagedItem](context.Background(), client, tc.endpoint, tc.opts))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if len(items) != tc.expectedItems {
+				t.Errorf("expected %d items, got %d", tc.expectedItems, len(items))
+			}
+			for i, item := range items {
+				if item.ID != i+1 {
+					t.Fatalf("expected item %d at index %d, got %d", i+1, i, item.ID)
+				}
+			}
+			if len(requests) != tc.expectedRequests {
+				t.Errorf("expected %d requests, got %d: %v", tc.expectedRequests, len(requests), requests)
+			}
+		})
+	}
+}
+
+// TestPaginateBreak tests that no further pages are requested after the loop ends
+func TestPaginateBreak(t *testing.T) {
+	var requests []string
+	client := newPagedClient(t, 250, &requests)
+
+	for item, err := range Paginate[pagedItem](context.Background(), client, "https://gitlab.com/api/v4/items", ListOptions{}) {
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if item.ID == 3 {
+			break
+		}
+	}
+
+	if len(requests) != 1 {
+		t.Errorf("expected 1 request, got %d", len(requests))
+	}
+}
+
+// TestListAllCap tests that lists capped by MaxListItems are reported
+func TestListAllCap(t *testing.T) {
+	tests := []struct {
+		name           string
+		total          int
+		expectedItems  int
+		expectedCapped []string
+	}{
+		{name: "below the cap", total: 2, expectedItems: 2},
+		{name: "at the cap", total: 3, expectedItems: 3},
+		{name: "above the cap", total: 250, expectedItems: 3, expectedCapped: []string{"items"}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var requests []string
+			client := newPagedClient(t, tc.total, &requests)
+			client.MaxListItems = 3
+
+			ctx, report := WithListCapReport(context.Background())
+			items, err := listAll[pagedItem](ctx, client, "https://gitlab.com/api/v4/items")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(items) != tc.expectedItems {
+				t.Errorf("expected %d items, got %d", tc.expectedItems, len(items))
+			}
+			if capped := report.Capped(); !slices.Equal(capped, tc.expectedCapped) {
+				t.Errorf("expected capped lists %v, got %v", tc.expectedCapped, capped)
+			}
+		})
+	}
+
+	// Merge request lookups ignore the cap
+	var requests []string
+	client := newPagedClient(t, 250, &requests)
+	client.MaxListItems = 3
+	items, err := listUncapped[pagedItem](context.Background(), client, "https://gitlab.com/api/v4/items")
+	if err != nil || len(items) != 250 {
+		t.Errorf("expected 250 items, got %d (%v)", len(items), err)
+	}
+}
+
+// TestNextPage tests the nextPage function
+func TestNextPage(t *testing.T) {
+	tests := []struct {
+		name        string
+		current     string
+		header      http.Header
+		expected    string
+		expectError bool
+	}{
+		{
+			name:     "last page",
+			current:  "https://gitlab.com/api/v4/projects/a%2Fb/merge_requests?page=1&per_page=100",
+			header:   http.Header{"X-Next-Page": []string{""}},
+			expected: "",
+		},
+		{
+			name:     "offset keeps escaped paths",
+			current:  "https://gitlab.com/api/v4/projects/a%2Fb/merge_requests?page=1&per_page=100",
+			header:   http.Header{"X-Next-Page": []string{"2"}},
+			expected: "https://gitlab.com/api/v4/projects/a%2Fb/merge_requests?page=2&per_page=100",
+		},
+		{
+			name:    "offset ignores links",
+			current: "https://gitlab.com/api/v4/projects/a%2Fb/merge_requests?page=1&per_page=100",
+			header: http.Header{
+				"X-Next-Page": []string{"2"},
+				"Link":        []string{`<http://gitlab.internal/api/v4/projects/a%2Fb/merge_requests?page=2&per_page=100>; rel="next"`},
+			},
+			expected: "https://gitlab.com/api/v4/projects/a%2Fb/merge_requests?page=2&per_page=100",
+		},
+		{
+			name:    "last offset page with a link",
+			current: "https://gitlab.com/api/v4/projects/a%2Fb/merge_requests?page=2&per_page=100",
+			header: http.Header{
+				"X-Next-Page": []string{""},
+				"Link":        []string{`<https://gitlab.com/api/v4/projects/a%2Fb/merge_requests?page=1&per_page=100>; rel="first"`},
+			},
+			expected: "",
+		},
+		{
+			name:     "link without keyset pagination",
+			current:  "https://gitlab.com/api/v4/projects?page=1",
+			header:   http.Header{"Link": []string{`</api/v4/projects?page=2>; rel="next"`}},
+			expected: "",
+		},
+		{
+			name:     "relative link",
+			current:  "https://gitlab.com/api/v4/projects?pagination=keyset",
+			header:   http.Header{"Link": []string{`</api/v4/projects?id_after=7&pagination=keyset>; rel="next"`}},
+			expected: "https://gitlab.com/api/v4/projects?id_after=7&pagination=keyset",
+		},
+		{
+			name:        "link to another host",
+			current:     "https://gitlab.com/api/v4/projects?pagination=keyset",
+			header:      http.Header{"Link": []string{`<https://evil.example.com/steal>; rel="next"`}},
+			expectError: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			next, err := nextPage(tc.current, tc.header)
+			if tc.expectError {
+				if err == nil {
+					t.Errorf("expected error, got %q", next)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if next != tc.expected {
+				t.Errorf("expected %q, got %q", tc.expected, next)
+			}
+		})
+	}
+}
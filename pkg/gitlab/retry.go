@@ -0,0 +1,226 @@
+// Package gitlab provides utilities for interacting with the GitLab API.
+package gitlab
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Defaults of RetryTransport.
+const (
+	DefaultMaxRetries = 3
+	DefaultBaseDelay  = 500 * time.Millisecond
+	DefaultMaxDelay   = 30 * time.Second
+)
+
+// RetryTransport is an http.RoundTripper retrying rate-limited requests and
+// transient failures. Rate-limited requests are retried after the delay
+// announced by GitLab, other failures of safe requests, and of requests whose
+// context was marked with WithRetry, with jittered exponential backoff.
+type RetryTransport struct {
+	Base http.RoundTripper
+
+	// MaxRetries is the number of retries after the first attempt.
+	MaxRetries int
+	// BaseDelay is the backoff before the first retry, doubled on every retry.
+	BaseDelay time.Duration
+	// MaxDelay caps any delay. Requests GitLab asks to delay longer are not retried.
+	MaxDelay time.Duration
+
+	// Logger receives debug logs, slog.Default() when nil.
+	Logger *slog.Logger
+
+	// sleep waits between attempts unless the context ends first. It can be
+	// replaced in tests.
+	sleep func(ctx context.Context, d time.Duration) error
+	// now returns the current time. It can be replaced in tests.
+	now func() time.Time
+}
+
+// NewRetryTransport wraps base with the default retry settings.
+func NewRetryTransport(base http.RoundTripper) *RetryTransport {
+	return &RetryTransport{
+		Base:       base,
+		MaxRetries: DefaultMaxRetries,
+		BaseDelay:  DefaultBaseDelay,
+		MaxDelay:   DefaultMaxDelay,
+	}
+}
+
+// retryKey is the context key of WithRetry.
+type retryKey struct{}
+
+// WithRetry marks the requests made with ctx as safe to repeat after
+// transient failures, for writes that have the same effect when applied
+// twice. Requests with other methods than GET, HEAD and OPTIONS are
+// otherwise only retried when rate-limited.
+func WithRetry(ctx context.Context) context.Context {
+	return context.WithValue(ctx, retryKey{}, true)
+}
+
+// isRetryable reports whether a request can be repeated after a transient
+// failure, which may happen after GitLab processed it.
+func isRetryable(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	}
+	optIn, _ := req.Context().Value(retryKey{}).(bool)
+	return optIn
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *RetryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	logger := t.Logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+	sleep := t.sleep
+	if sleep == nil {
+		sleep = sleepContext
+	}
+
+	for attempt := 0; ; attempt++ {
+		attemptReq := req
+		if attempt > 0 && req.Body != nil && req.Body != http.NoBody {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			attemptReq = req.Clone(req.Context())
+			attemptReq.Body = body
+		}
+
+		resp, err := base.RoundTrip(attemptReq)
+		if resp != nil {
+			logRateLimit(logger, req, resp)
+		}
+
+		// A consumed body can only be sent again when it can be recreated
+		replayable := req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+
+		delay, retry := t.retryDelay(req, resp, err, attempt)
+		if !retry || !replayable || attempt >= t.MaxRetries {
+			return resp, err
+		}
+
+		if err != nil {
+			logger.Debug("retrying GitLab request", "method", req.Method, "url", req.URL.Redacted(), "error", err, "delay", delay)
+		} else {
+			logger.Debug("retrying GitLab request", "method", req.Method, "url", req.URL.Redacted(), "status", resp.StatusCode, "delay", delay)
+			// Drain the body so the connection can be reused
+			_, _ = io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+
+		// Do not outlive the request
+		if err := sleep(req.Context(), delay); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// sleepContext waits for d or until ctx ends.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// retryDelay decides whether a request is retried and after which delay.
+func (t *RetryTransport) retryDelay(req *http.Request, resp *http.Response, err error, attempt int) (time.Duration, bool) {
+	if err != nil {
+		// Network errors may happen after the request was processed
+		if req.Context().Err() != nil || !isRetryable(req) {
+			return 0, false
+		}
+		return t.backoff(attempt), true
+	}
+
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests:
+		// Rate-limited requests were not processed, so any method is retried
+		if delay, ok := t.announcedDelay(resp.Header); ok {
+			return delay, delay <= t.MaxDelay
+		}
+		return t.backoff(attempt), true
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		if !isRetryable(req) {
+			return 0, false
+		}
+		if delay, ok := t.announcedDelay(resp.Header); ok {
+			return delay, delay <= t.MaxDelay
+		}
+		return t.backoff(attempt), true
+	}
+
+	return 0, false
+}
+
+// announcedDelay reads the delay announced by the Retry-After header, in
+// seconds or as an HTTP date, or by the RateLimit-Reset Unix timestamp.
+func (t *RetryTransport) announcedDelay(header http.Header) (time.Duration, bool) {
+	now := time.Now
+	if t.now != nil {
+		now = t.now
+	}
+
+	if retryAfter := header.Get("Retry-After"); retryAfter != "" {
+		if seconds, err := strconv.Atoi(retryAfter); err == nil {
+			return max(time.Duration(seconds)*time.Second, 0), true
+		}
+		if date, err := http.ParseTime(retryAfter); err == nil {
+			return max(date.Sub(now()), 0), true
+		}
+	}
+
+	if reset := header.Get("RateLimit-Reset"); reset != "" {
+		if timestamp, err := strconv.ParseInt(reset, 10, 64); err == nil {
+			return max(time.Unix(timestamp, 0).Sub(now()), 0), true
+		}
+	}
+
+	return 0, false
+}
+
+// backoff returns the jittered exponential delay before a retry.
+func (t *RetryTransport) backoff(attempt int) time.Duration {
+	delay := t.BaseDelay << attempt
+	if delay <= 0 || delay > t.MaxDelay {
+		delay = t.MaxDelay
+	}
+
+	// Full jitter spreads the retries of concurrent calls
+	return time.Duration(rand.Int64N(int64(delay) + 1))
+}
+
+// logRateLimit logs the remaining rate limit reported by GitLab.
+func logRateLimit(logger *slog.Logger, req *http.Request, resp *http.Response) {
+	remaining := resp.Header.Get("RateLimit-Remaining")
+	if remaining == "" {
+		return
+	}
+
+	logger.Debug("GitLab rate limit",
+		"method", req.Method,
+		"url", req.URL.Redacted(),
+		"remaining", remaining,
+		"limit", resp.Header.Get("RateLimit-Limit"),
+		"reset", resp.Header.Get("RateLimit-Reset"),
+	)
+}
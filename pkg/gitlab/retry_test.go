@@ -0,0 +1,231 @@
+package gitlab
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// roundTripperFunc adapts a function to http.RoundTripper
+type roundTripperFunc func(req *http.Request) (*http.Response, error)
+
+// RoundTrip implements http.RoundTripper
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestRetryTransport(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	errNetwork := errors.New("connection reset")
+
+	tests := []struct {
+		name           string
+		method         string
+		responses      []int
+		headers        http.Header
+		networkError   bool
+		withRetry      bool
+		expectedCalls  int
+		expectedStatus int
+		expectedDelays []time.Duration
+	}{
+		{
+			name:           "transient failure of a GET",
+			method:         http.MethodGet,
+			responses:      []int{http.StatusServiceUnavailable, http.StatusOK},
+			expectedCalls:  2,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "transient failure of a POST",
+			method:         http.MethodPost,
+			responses:      []int{http.StatusBadGateway},
+			expectedCalls:  1,
+			expectedStatus: http.StatusBadGateway,
+		},
+		{
+			name:           "transient failure of a PUT",
+			method:         http.MethodPut,
+			responses:      []int{http.StatusServiceUnavailable},
+			expectedCalls:  1,
+			expectedStatus: http.StatusServiceUnavailable,
+		},
+		{
+			name:           "transient failure of a PUT marked with WithRetry",
+			method:         http.MethodPut,
+			responses:      []int{http.StatusServiceUnavailable, http.StatusOK},
+			withRetry:      true,
+			expectedCalls:  2,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "rate-limited POST with Retry-After",
+			method:         http.MethodPost,
+			responses:      []int{http.StatusTooManyRequests, http.StatusCreated},
+			headers:        http.Header{"Retry-After": {"2"}},
+			expectedCalls:  2,
+			expectedStatus: http.StatusCreated,
+			expectedDelays: []time.Duration{2 * time.Second},
+		},
+		{
+			name:           "rate-limited with RateLimit-Reset",
+			method:         http.MethodGet,
+			responses:      []int{http.StatusTooManyRequests, http.StatusOK},
+			headers:        http.Header{"Ratelimit-Reset": {strconv.FormatInt(now.Unix()+5, 10)}},
+			expectedCalls:  2,
+			expectedStatus: http.StatusOK,
+			expectedDelays: []time.Duration{5 * time.Second},
+		},
+		{
+			name:           "rate-limited for too long",
+			method:         http.MethodGet,
+			responses:      []int{http.StatusTooManyRequests},
+			headers:        http.Header{"Retry-After": {"3600"}},
+			expectedCalls:  1,
+			expectedStatus: http.StatusTooManyRequests,
+		},
+		{
+			name:           "retries exhausted",
+			method:         http.MethodGet,
+			responses:      []int{http.StatusGatewayTimeout, http.StatusGatewayTimeout, http.StatusGatewayTimeout, http.StatusGatewayTimeout},
+			expectedCalls:  4,
+			expectedStatus: http.StatusGatewayTimeout,
+		},
+		{
+			name:           "not found",
+			method:         http.MethodGet,
+			responses:      []int{http.StatusNotFound},
+			expectedCalls:  1,
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "network error of a GET",
+			method:         http.MethodGet,
+			responses:      []int{0, http.StatusOK},
+			networkError:   true,
+			expectedCalls:  2,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:          "network error of a POST",
+			method:        http.MethodPost,
+			responses:     []int{0},
+			networkError:  true,
+			expectedCalls: 1,
+		},
+		{
+			name:          "network error of a DELETE",
+			method:        http.MethodDelete,
+			responses:     []int{0},
+			networkError:  true,
+			expectedCalls: 1,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			calls := 0
+			var delays []time.Duration
+
+			transport := NewRetryTransport(roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+				status := tc.responses[calls]
+				calls++
+
+				// Every attempt sends the whole body
+				if req.Body != nil {
+					body, _ := io.ReadAll(req.Body)
+					if string(body) != `{"body":"hi"}` {
+						t.Errorf("unexpected body %q", body)
+					}
+				}
+
+				if status == 0 {
+					return nil, errNetwork
+				}
+				return &http.Response{
+					StatusCode: status,
+					Header:     tc.headers,
+					Body:       io.NopCloser(bytes.NewBufferString("")),
+				}, nil
+			}))
+			transport.now = func() time.Time { return now }
+			transport.sleep = func(ctx context.Context, d time.Duration) error {
+				delays = append(delays, d)
+				return nil
+			}
+
+			ctx := context.Background()
+			if tc.withRetry {
+				ctx = WithRetry(ctx)
+			}
+			req, _ := http.NewRequestWithContext(ctx, tc.method, "https://gitlab.com/api/v4/user", bytes.NewBufferString(`{"body":"hi"}`))
+			resp, err := transport.RoundTrip(req)
+
+			if calls != tc.expectedCalls {
+				t.Errorf("expected %d call(s), got %d", tc.expectedCalls, calls)
+			}
+			if tc.expectedStatus == 0 {
+				if !errors.Is(err, errNetwork) {
+					t.Errorf("expected the network error, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if resp.StatusCode != tc.expectedStatus {
+				t.Errorf("expected status %d, got %d", tc.expectedStatus, resp.StatusCode)
+			}
+			if tc.expectedDelays != nil && !slices.Equal(delays, tc.expectedDelays) {
+				t.Errorf("expected delays %v, got %v", tc.expectedDelays, delays)
+			}
+			for _, delay := range delays {
+				if delay < 0 || delay > DefaultMaxDelay {
+					t.Errorf("delay %v out of bounds", delay)
+				}
+			}
+		})
+	}
+}
+
+func TestRetryTransportCancelled(t *testing.T) {
+	calls := 0
+	transport := NewRetryTransport(roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		calls++
+		return &http.Response{
+			StatusCode: http.StatusServiceUnavailable,
+			Body:       io.NopCloser(bytes.NewBufferString("")),
+		}, nil
+	}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	transport.sleep = func(ctx context.Context, d time.Duration) error {
+		cancel()
+		return sleepContext(ctx, d)
+	}
+
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, "https://gitlab.com/api/v4/user", nil)
+	_, err := transport.RoundTrip(req)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("expected 1 call, got %d", calls)
+	}
+}
+
+func TestRetryTransportBackoff(t *testing.T) {
+	transport := NewRetryTransport(nil)
+	for attempt := 0; attempt < 10; attempt++ {
+		limit := min(DefaultBaseDelay<<attempt, DefaultMaxDelay)
+		if delay := transport.backoff(attempt); delay < 0 || delay > limit {
+			t.Errorf("attempt %d: delay %v not within [0, %v]", attempt, delay, limit)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+// Package gitlab provides utilities for interacting with the GitLab API.
+package gitlab
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SuggestionBlock is a ```suggestion block of a comment body. LinesAbove and
+// LinesBelow come from the optional ":-A+B" offset and extend the replaced
+// range around the commented line.
+type SuggestionBlock struct {
+	LinesAbove int
+	LinesBelow int
+	Lines      []string
+}
+
+// ParseSuggestions extracts the suggestion blocks from a comment body.
+func ParseSuggestions(body string) []SuggestionBlock {
+	var suggestions []SuggestionBlock
+
+	lines := strings.Split(strings.ReplaceAll(body, "\r\n", "\n"), "\n")
+	for i := 0; i < len(lines); i++ {
+		line := strings.TrimSpace(lines[i])
+
+		// Fences may use three or more backticks and must be closed by the same
+		fence := line[:len(line)-len(strings.TrimLeft(line, "`"))]
+		if len(fence) < 3 || !strings.HasPrefix(line[len(fence):], "suggestion") {
+			continue
+		}
+
+		block := SuggestionBlock{Lines: []string{}}
+		offset := strings.TrimPrefix(line[len(fence):], "suggestion")
+		if offset != "" {
+			if _, err := fmt.Sscanf(offset, ":-%d+%d", &block.LinesAbove, &block.LinesBelow); err != nil {
+				continue
+			}
+		}
+
+		closed := false
+		for i++; i < len(lines); i++ {
+			if strings.TrimSpace(lines[i]) == fence {
+				closed = true
+				break
+			}
+			block.Lines = append(block.Lines, lines[i])
+		}
+		if closed {
+			suggestions = append(suggestions, block)
+		}
+	}
+
+	return suggestions
+}
+
+// LineRange returns the first and last line replaced by the suggestion when
+// it is attached to the given line.
+func (s SuggestionBlock) LineRange(line int) (int, int) {
+	return line - s.LinesAbove, line + s.LinesBelow
+}
+
+// SuggestionBlocks returns the suggestion blocks parsed from the note body.
+func (n MergeRequestNote) SuggestionBlocks() []SuggestionBlock {
+	return ParseSuggestions(n.Body)
+}
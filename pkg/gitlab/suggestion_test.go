@@ -0,0 +1,66 @@
+package gitlab
+
+import (
+	"reflect"
+	"testing"
+)
+
+// TestParseSuggestions tests the ParseSuggestions function
+func TestParseSuggestions(t *testing.T) {
+	// Test cases
+	tests := []struct {
+		name     string
+		body     string
+		expected []SuggestionBlock
+	}{
+		{
+			name:     "no suggestions",
+			body:     "Please rename this.\n```go\nfoo()\n```",
+			expected: nil,
+		},
+		{
+			name: "single line suggestion",
+			body: "Typo:\n```suggestion\nfmt.Println(\"hello\")\n```\n",
+			expected: []SuggestionBlock{
+				{Lines: []string{`fmt.Println("hello")`}},
+			},
+		},
+		{
+			name: "offsets and several blocks",
+			body: "```suggestion:-0+2\na\nb\n```\nor\n````suggestion:-1+0\n```go\n````",
+			expected: []SuggestionBlock{
+				{LinesAbove: 0, LinesBelow: 2, Lines: []string{"a", "b"}},
+				{LinesAbove: 1, LinesBelow: 0, Lines: []string{"```go"}},
+			},
+		},
+		{
+			name: "empty suggestion removes lines",
+			body: "```suggestion\n```",
+			expected: []SuggestionBlock{
+				{Lines: []string{}},
+			},
+		},
+		{
+			name:     "unterminated block",
+			body:     "```suggestion\nfoo",
+			expected: nil,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			suggestions := ParseSuggestions(tc.body)
+			if !reflect.DeepEqual(suggestions, tc.expected) {
+				t.Errorf("expected %+v, got %+v", tc.expected, suggestions)
+			}
+		})
+	}
+}
+
+// TestSuggestionLineRange tests the LineRange method
+func TestSuggestionLineRange(t *testing.T) {
+	start, end := SuggestionBlock{LinesAbove: 2, LinesBelow: 1}.LineRange(10)
+	if start != 8 || end != 11 {
+		t.Errorf("expected 8-11, got %d-%d", start, end)
+	}
+}
@@ -0,0 +1,138 @@
+// Package gitlab provides utilities for interacting with the GitLab API.
+package gitlab
+
+import (
+	"errors"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// TraceLine is a single line of a CI job log without terminal formatting.
+type TraceLine struct {
+	// Number is the 1-based line number in the raw log.
+	Number int
+	Text   string
+	// Section is the name of the innermost section the line belongs to.
+	Section string
+	// Collapsed reports that the line is inside a section collapsed by default.
+	Collapsed bool
+	// CollapsedSection is the name of the outermost collapsed section the
+	// line is inside, which hides it.
+	CollapsedSection string
+}
+
+var (
+	// sectionMarkerPattern matches the markers GitLab emits around log sections, e.g.
+	// "section_start:1560896352:build[collapsed=true]\r\x1b[0K".
+	sectionMarkerPattern = regexp.MustCompile(`(?:\x1b\[0K)?section_(start|end):\d+:([^\s\[\r]+)(\[[^\]]*\])?\r?(?:\x1b\[0K)?`)
+
+	// ansiPattern matches ANSI escape sequences, such as colours.
+	ansiPattern = regexp.MustCompile(`\x1b\[[0-9;?]*[A-Za-z]`)
+)
+
+// traceSection is an open section of a job log.
+type traceSection struct {
+	name      string
+	collapsed bool
+}
+
+// ParseJobTrace splits a raw job log into lines, removing ANSI escapes and
+// section markers. Carriage returns are handled like a terminal would, so only
+// the final state of progress bars is kept.
+func ParseJobTrace(trace string) []TraceLine {
+	var lines []TraceLine
+	var sections []traceSection
+
+	for i, raw := range strings.Split(strings.TrimSuffix(trace, "\n"), "\n") {
+		// The header of a section belongs to it, but stays visible when collapsed
+		collapsedBefore := collapsedSection(sections)
+
+		for _, match := range sectionMarkerPattern.FindAllStringSubmatch(raw, -1) {
+			if match[1] == "start" {
+				sections = append(sections, traceSection{
+					name:      match[2],
+					collapsed: strings.Contains(match[3], "collapsed=true"),
+				})
+				continue
+			}
+			// Close the section and any unterminated sections nested in it
+			for j := len(sections) - 1; j >= 0; j-- {
+				if sections[j].name == match[2] {
+					sections = sections[:j]
+					break
+				}
+			}
+		}
+
+		text := sectionMarkerPattern.ReplaceAllString(raw, "")
+		text = ansiPattern.ReplaceAllString(text, "")
+		text = strings.TrimSuffix(text, "\r")
+		if idx := strings.LastIndex(text, "\r"); idx >= 0 {
+			text = text[idx+1:]
+		}
+
+		// Lines consisting only of markers carry no text
+		if text == "" && raw != "" && sectionMarkerPattern.MatchString(raw) {
+			continue
+		}
+
+		line := TraceLine{
+			Number:           i + 1,
+			Text:             text,
+			Collapsed:        collapsedBefore != "",
+			CollapsedSection: collapsedBefore,
+		}
+		if len(sections) > 0 {
+			line.Section = sections[len(sections)-1].name
+		}
+		lines = append(lines, line)
+	}
+
+	return lines
+}
+
+// collapsedSection returns the name of the outermost collapsed section of the
+// open sections, or an empty string when none is collapsed.
+func collapsedSection(sections []traceSection) string {
+	for _, section := range sections {
+		if section.collapsed {
+			return section.name
+		}
+	}
+	return ""
+}
+
+// traceTail receives the last max bytes of a job log.
+type traceTail struct {
+	max  int
+	data []byte
+	// truncated reports that the start of the log was cut.
+	truncated bool
+}
+
+// readFrom reads r, keeping at most max bytes from its end.
+func (t *traceTail) readFrom(r io.Reader) error {
+	buf := make([]byte, 32*1024)
+	for {
+		n, err := r.Read(buf)
+		t.data = append(t.data, buf[:n]...)
+		// Dropping the start only once twice the size is buffered saves copies
+		if len(t.data) > 2*t.max {
+			t.data = append(t.data[:0], t.data[len(t.data)-t.max:]...)
+			t.truncated = true
+		}
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(t.data) > t.max {
+		t.data = t.data[len(t.data)-t.max:]
+		t.truncated = true
+	}
+	return nil
+}
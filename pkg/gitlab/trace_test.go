@@ -0,0 +1,52 @@
+package gitlab
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseJobTrace(t *testing.T) {
+	trace := "\x1b[0KRunning with gitlab-runner 16.0\x1b[0;m\n" +
+		"section_start:1700000000:prepare_script\r\x1b[0K\x1b[36;1mPreparing environment\x1b[0;m\n" +
+		"Running on runner-1\n" +
+		"\x1b[0Ksection_end:1700000001:prepare_script\r\x1b[0K\n" +
+		"section_start:1700000002:deps[collapsed=true]\r\x1b[0KInstalling dependencies\n" +
+		"Downloading 10%\rDownloading 100%\r\n" +
+		"\x1b[0Ksection_end:1700000003:deps\r\x1b[0K\n" +
+		"\x1b[31;1mERROR: Job failed: exit code 1\x1b[0;m\n"
+
+	expected := []TraceLine{
+		{Number: 1, Text: "Running with gitlab-runner 16.0"},
+		{Number: 2, Text: "Preparing environment", Section: "prepare_script"},
+		{Number: 3, Text: "Running on runner-1", Section: "prepare_script"},
+		{Number: 5, Text: "Installing dependencies", Section: "deps"},
+		{Number: 6, Text: "Downloading 100%", Section: "deps", Collapsed: true, CollapsedSection: "deps"},
+		{Number: 8, Text: "ERROR: Job failed: exit code 1"},
+	}
+
+	actual := ParseJobTrace(trace)
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected %+v, got %+v", expected, actual)
+	}
+}
+
+func TestParseJobTraceNestedSections(t *testing.T) {
+	trace := "section_start:1:build[collapsed=true]\r\x1b[0KBuilding\n" +
+		"compiling\n" +
+		"section_start:2:assets\r\x1b[0KBuilding assets\n" +
+		"minifying\n" +
+		"section_end:3:assets\r\x1b[0K\n" +
+		"section_end:4:build\r\x1b[0K\n"
+
+	expected := []TraceLine{
+		{Number: 1, Text: "Building", Section: "build"},
+		{Number: 2, Text: "compiling", Section: "build", Collapsed: true, CollapsedSection: "build"},
+		{Number: 3, Text: "Building assets", Section: "assets", Collapsed: true, CollapsedSection: "build"},
+		{Number: 4, Text: "minifying", Section: "assets", Collapsed: true, CollapsedSection: "build"},
+	}
+
+	actual := ParseJobTrace(trace)
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected %+v, got %+v", expected, actual)
+	}
+}
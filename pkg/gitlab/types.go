@@ -1,10 +1,13 @@
 // Package gitlab provides utilities for interacting with the GitLab API.
 package gitlab
 
+import "fmt"
+
 // MergeRequest represents a GitLab merge request.
 type MergeRequest struct {
 	ID           int    `json:"id"`
 	IID          int    `json:"iid"`
+	ProjectID    int    `json:"project_id"`
 	Title        string `json:"title"`
 	Description  string `json:"description"`
 	WebURL       string `json:"web_url"`
@@ -14,6 +17,18 @@ type MergeRequest struct {
 	Author       *struct {
 		UserName *string `json:"username"` // Use pointer in case it's null
 	} `json:"author,omitempty"`
+	References struct {
+		Full string `json:"full"`
+	} `json:"references"`
+}
+
+// Reference returns the full reference of the merge request, e.g.
+// group/project!12, or !IID when GitLab did not report it.
+func (mr MergeRequest) Reference() string {
+	if mr.References.Full != "" {
+		return mr.References.Full
+	}
+	return fmt.Sprintf("!%d", mr.IID)
 }
 
 // MergeRequestNote represents a comment on a GitLab merge request.
@@ -23,16 +38,194 @@ type MergeRequestNote struct {
 	Author struct {
 		Username string `json:"username"`
 	} `json:"author"`
-	System    bool   `json:"system"`
-	CreatedAt string `json:"created_at"`
-	Resolved  bool   `json:"resolved"`
-	Position  *struct {
-		NewPath   *string `json:"new_path"` // Use pointer in case it's null
-		NewLine   *int    `json:"new_line"` // Use pointer in case it's null
-		LineRange *struct {
-			Start *struct {
-				LineCode string `json:"line_code"`
-			} `json:"start,omitempty"`
-		} `json:"line_range,omitempty"`
-	} `json:"position,omitempty"`
+	System     bool          `json:"system"`
+	CreatedAt  string        `json:"created_at"`
+	Resolvable bool          `json:"resolvable"`
+	Resolved   bool          `json:"resolved"`
+	Position   *NotePosition `json:"position,omitempty"`
+
+	// Suggestions are the suggestion blocks of the body as tracked by GitLab
+	Suggestions []Suggestion `json:"suggestions,omitempty"`
+}
+
+// Suggestion represents a change suggested in a diff note.
+type Suggestion struct {
+	ID          int    `json:"id"`
+	FromLine    int    `json:"from_line"`
+	ToLine      int    `json:"to_line"`
+	Appliable   bool   `json:"appliable"`
+	Applied     bool   `json:"applied"`
+	FromContent string `json:"from_content"`
+	ToContent   string `json:"to_content"`
+}
+
+// NotePosition describes where a diff note is anchored in the merge request.
+type NotePosition struct {
+	BaseSHA      string     `json:"base_sha"`
+	StartSHA     string     `json:"start_sha"`
+	HeadSHA      string     `json:"head_sha"`
+	PositionType string     `json:"position_type"` // "text", "image" or "file"
+	OldPath      *string    `json:"old_path"`      // Use pointer in case it's null
+	NewPath      *string    `json:"new_path"`      // Use pointer in case it's null
+	OldLine      *int       `json:"old_line"`      // Set for removed and unchanged lines
+	NewLine      *int       `json:"new_line"`      // Set for added and unchanged lines
+	LineRange    *LineRange `json:"line_range,omitempty"`
+
+	// Image diff positions
+	Width  *int `json:"width,omitempty"`
+	Height *int `json:"height,omitempty"`
+	X      *int `json:"x,omitempty"`
+	Y      *int `json:"y,omitempty"`
+}
+
+// LineRange describes the lines covered by a multi-line diff note.
+type LineRange struct {
+	Start *LinePosition `json:"start,omitempty"`
+	End   *LinePosition `json:"end,omitempty"`
+}
+
+// LinePosition identifies a single line of a multi-line diff note.
+type LinePosition struct {
+	LineCode string `json:"line_code"`
+	Type     string `json:"type"`     // "new", "old" or empty for unchanged lines
+	OldLine  *int   `json:"old_line"` // Use pointer in case it's null
+	NewLine  *int   `json:"new_line"` // Use pointer in case it's null
+}
+
+// FilePath returns the path of the file the note is anchored to, falling back
+// to the old path for notes on removed files.
+func (p NotePosition) FilePath() string {
+	if p.NewPath != nil && *p.NewPath != "" {
+		return *p.NewPath
+	}
+	if p.OldPath != nil && *p.OldPath != "" {
+		return *p.OldPath
+	}
+	return "Unknown"
+}
+
+// IsImage reports whether the note is anchored to a point on an image diff.
+func (p NotePosition) IsImage() bool {
+	return p.PositionType == "image"
+}
+
+// Discussion represents a discussion thread on a GitLab merge request.
+type Discussion struct {
+	ID             string             `json:"id"`
+	IndividualNote bool               `json:"individual_note"`
+	Notes          []MergeRequestNote `json:"notes"`
+}
+
+// IsResolvable reports whether the discussion thread can be resolved.
+func (d Discussion) IsResolvable() bool {
+	for _, note := range d.Notes {
+		if note.Resolvable {
+			return true
+		}
+	}
+	return false
+}
+
+// IsResolved reports whether every resolvable note of the thread is resolved.
+func (d Discussion) IsResolved() bool {
+	if !d.IsResolvable() {
+		return false
+	}
+	for _, note := range d.Notes {
+		if note.Resolvable && !note.Resolved {
+			return false
+		}
+	}
+	return true
+}
+
+// User represents a GitLab user.
+type User struct {
+	ID       int    `json:"id"`
+	Username string `json:"username"`
+	Name     string `json:"name"`
+}
+
+// MergeRequestDiff represents the changes of a single file in a merge request.
+type MergeRequestDiff struct {
+	OldPath     string `json:"old_path"`
+	NewPath     string `json:"new_path"`
+	AMode       string `json:"a_mode"`
+	BMode       string `json:"b_mode"`
+	Diff        string `json:"diff"`
+	NewFile     bool   `json:"new_file"`
+	RenamedFile bool   `json:"renamed_file"`
+	DeletedFile bool   `json:"deleted_file"`
+}
+
+// MergeRequestVersion represents a diff version of a merge request, created on every push.
+type MergeRequestVersion struct {
+	ID             int                `json:"id"`
+	HeadCommitSHA  string             `json:"head_commit_sha"`
+	BaseCommitSHA  string             `json:"base_commit_sha"`
+	StartCommitSHA string             `json:"start_commit_sha"`
+	CreatedAt      string             `json:"created_at"`
+	State          string             `json:"state"`
+	Diffs          []MergeRequestDiff `json:"diffs,omitempty"`
+}
+
+// DraftNote represents an unpublished comment of a merge request review.
+type DraftNote struct {
+	ID                int           `json:"id"`
+	AuthorID          int           `json:"author_id"`
+	MergeRequestID    int           `json:"merge_request_id"`
+	ResolveDiscussion bool          `json:"resolve_discussion"`
+	DiscussionID      string        `json:"discussion_id"`
+	Note              string        `json:"note"`
+	CommitID          string        `json:"commit_id"`
+	LineCode          string        `json:"line_code"`
+	Position          *NotePosition `json:"position,omitempty"`
+}
+
+// CreateDraftNoteOptions holds the fields of a new draft note.
+type CreateDraftNoteOptions struct {
+	Note                  string        `json:"note"`
+	Position              *NotePosition `json:"position,omitempty"`
+	InReplyToDiscussionID string        `json:"in_reply_to_discussion_id,omitempty"`
+	ResolveDiscussion     bool          `json:"resolve_discussion,omitempty"`
+}
+
+// Pipeline represents a CI pipeline.
+type Pipeline struct {
+	ID         int    `json:"id"`
+	IID        int    `json:"iid"`
+	ProjectID  int    `json:"project_id"`
+	SHA        string `json:"sha"`
+	Ref        string `json:"ref"`
+	Status     string `json:"status"`
+	Source     string `json:"source"`
+	WebURL     string `json:"web_url"`
+	CreatedAt  string `json:"created_at"`
+	UpdatedAt  string `json:"updated_at"`
+	StartedAt  string `json:"started_at,omitempty"`
+	FinishedAt string `json:"finished_at,omitempty"`
+
+	// Duration is in seconds and only reported for single pipelines
+	Duration *int `json:"duration,omitempty"`
+}
+
+// Job represents a CI job of a pipeline.
+type Job struct {
+	ID            int      `json:"id"`
+	Name          string   `json:"name"`
+	Stage         string   `json:"stage"`
+	Status        string   `json:"status"`
+	AllowFailure  bool     `json:"allow_failure"`
+	FailureReason string   `json:"failure_reason,omitempty"`
+	WebURL        string   `json:"web_url"`
+	StartedAt     string   `json:"started_at,omitempty"`
+	FinishedAt    string   `json:"finished_at,omitempty"`
+	Duration      *float64 `json:"duration,omitempty"` // In seconds, null for jobs that did not run
+}
+
+// Bridge represents a trigger job, which starts a child or downstream pipeline.
+type Bridge struct {
+	Job
+	// DownstreamPipeline is nil until the triggered pipeline was created
+	DownstreamPipeline *Pipeline `json:"downstream_pipeline"`
 }
@@ -10,11 +10,18 @@ import (
 )
 
 // GetCurrentBranchHandler handles the getCurrentBranch tool request.
-func GetCurrentBranchHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	branch, err := git.GetCurrentBranch()
+func GetCurrentBranchHandler(ctx context.Context, request mcp.CallToolRequest, config Config) (*mcp.CallToolResult, error) {
+	state, err := git.GetRepositoryState(repoPath(request, config))
 	if err != nil {
-		return mcp.NewToolResultError(err.Error()), nil
+		return toolError(err), nil
 	}
 
-	return mcp.NewToolResultText(fmt.Sprintf("Current branch is: %s", branch)), nil
-}
\ No newline at end of file
+	switch {
+	case !state.Detached && state.Operation == "":
+		return mcp.NewToolResultText(fmt.Sprintf("Current branch is: %s", state.Branch)), nil
+	case state.Branch != "":
+		return mcp.NewToolResultText(fmt.Sprintf("Current branch is: %s (%s)", state.Branch, state)), nil
+	default:
+		return mcp.NewToolResultText(fmt.Sprintf("No branch is checked out (%s), merge requests are looked up by the HEAD commit", state)), nil
+	}
+}
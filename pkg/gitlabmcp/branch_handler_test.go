@@ -11,26 +11,42 @@ import (
 
 // TestGetCurrentBranchHandler tests the GetCurrentBranchHandler function
 func TestGetCurrentBranchHandler(t *testing.T) {
-	// Save the original GetCurrentBranch function and restore it after the test
-	originalGetCurrentBranch := git.GetCurrentBranch
-	defer func() { git.GetCurrentBranch = originalGetCurrentBranch }()
+	// Save the original GetRepositoryState function and restore it after the test
+	originalGetRepositoryState := git.GetRepositoryState
+	defer func() { git.GetRepositoryState = originalGetRepositoryState }()
 
 	// Test cases
 	tests := []struct {
 		name           string
-		mockBranch     string
+		mockState      git.RepositoryState
 		mockError      error
 		expectErrorMsg string
+		expectedText   string
 	}{
 		{
 			name:           "successful branch retrieval",
-			mockBranch:     "main",
+			mockState:      git.RepositoryState{Branch: "main"},
 			mockError:      nil,
 			expectErrorMsg: "",
+			expectedText:   "Current branch is: main",
+		},
+		{
+			name:           "rebase in progress",
+			mockState:      git.RepositoryState{Branch: "feature", HeadSHA: "0123456789abcdef", Detached: true, Operation: git.OperationRebase},
+			mockError:      nil,
+			expectErrorMsg: "",
+			expectedText:   "Current branch is: feature (HEAD detached at 01234567 while working on branch feature, rebase in progress)",
+		},
+		{
+			name:           "detached HEAD",
+			mockState:      git.RepositoryState{HeadSHA: "0123456789abcdef", Detached: true},
+			mockError:      nil,
+			expectErrorMsg: "",
+			expectedText:   "No branch is checked out (HEAD detached at 01234567), merge requests are looked up by the HEAD commit",
 		},
 		{
 			name:           "git command error",
-			mockBranch:     "",
+			mockState:      git.RepositoryState{},
 			mockError:      errors.New("git command failed"),
 			expectErrorMsg: "git command failed",
 		},
@@ -38,13 +54,13 @@ func TestGetCurrentBranchHandler(t *testing.T) {
 
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
-			// Mock the git.GetCurrentBranch function
-			git.GetCurrentBranch = func() (string, error) {
-				return tc.mockBranch, tc.mockError
+			// Mock the git.GetRepositoryState function
+			git.GetRepositoryState = func(dir string) (git.RepositoryState, error) {
+				return tc.mockState, tc.mockError
 			}
 
 			// Call the handler
-			result, err := GetCurrentBranchHandler(context.Background(), mcp.CallToolRequest{})
+			result, err := GetCurrentBranchHandler(context.Background(), mcp.CallToolRequest{}, Config{})
 
 			// Check for unexpected errors
 			if err != nil {
@@ -85,11 +101,10 @@ func TestGetCurrentBranchHandler(t *testing.T) {
 					return
 				}
 
-				expectedText := "Current branch is: " + tc.mockBranch
-				if textContent.Text != expectedText {
-					t.Errorf("expected text %q, got %q", expectedText, textContent.Text)
+				if textContent.Text != tc.expectedText {
+					t.Errorf("expected text %q, got %q", tc.expectedText, textContent.Text)
 				}
 			}
 		})
 	}
-}
\ No newline at end of file
+}
@@ -0,0 +1,95 @@
+// Package gitlabmcp provides the GitLab MCP tool functionality.
+package gitlabmcp
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/ondratuma/gitlab-review-mcp/pkg/git"
+	"github.com/ondratuma/gitlab-review-mcp/pkg/gitlab"
+)
+
+// FormatMergeRequestDiff formats the hunks of a single changed file.
+func FormatMergeRequestDiff(diff gitlab.MergeRequestDiff) (string, error) {
+	hunks, err := git.ParseHunks(diff.Diff)
+	if err != nil {
+		return "", err
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("File: %s\n", diff.NewPath))
+
+	switch {
+	case diff.NewFile:
+		sb.WriteString("Status: added\n")
+	case diff.DeletedFile:
+		sb.WriteString("Status: deleted\n")
+	case diff.RenamedFile:
+		sb.WriteString(fmt.Sprintf("Status: renamed from %s\n", diff.OldPath))
+	default:
+		sb.WriteString("Status: modified\n")
+	}
+
+	if len(hunks) == 0 {
+		sb.WriteString("No textual changes\n")
+	}
+
+	for _, hunk := range hunks {
+		sb.WriteString(fmt.Sprintf("\n%s\n", hunk.Header))
+		for _, line := range hunk.Lines {
+			sb.WriteString(line)
+			sb.WriteString("\n")
+		}
+	}
+
+	return sb.String(), nil
+}
+
+// GetMergeRequestChangesHandler handles the getMergeRequestChanges tool request.
+func GetMergeRequestChangesHandler(ctx context.Context, request mcp.CallToolRequest, client *gitlab.Client, config Config) (*mcp.CallToolResult, error) {
+	pathGlob := request.GetString("pathGlob", "")
+
+	mr, err := ResolveMergeRequest(ctx, request, client, config)
+	if err != nil {
+		return toolError(err), nil
+	}
+
+	diffs, err := client.GetMergeRequestDiffs(ctx, mr.ProjectID, mr.IID)
+	if err != nil {
+		return toolError(err), nil
+	}
+
+	contents := []mcp.Content{}
+	for _, diff := range diffs {
+		if pathGlob != "" {
+			// Match either side so renamed and deleted files are found too
+			matchesNew, err := MatchPathGlob(pathGlob, diff.NewPath)
+			if err != nil {
+				return toolError(err), nil
+			}
+			matchesOld, _ := MatchPathGlob(pathGlob, diff.OldPath)
+			if !matchesNew && !matchesOld {
+				continue
+			}
+		}
+
+		text, err := FormatMergeRequestDiff(diff)
+		if err != nil {
+			return toolError(err), nil
+		}
+
+		contents = append(contents, mcp.TextContent{
+			Type: "text",
+			Text: text,
+		})
+	}
+
+	header := fmt.Sprintf("Changes for Merge Request !%d (%d file(s))", mr.IID, len(contents))
+	contents = append([]mcp.Content{mcp.TextContent{Type: "text", Text: header}}, contents...)
+
+	return &mcp.CallToolResult{
+		Content: contents,
+	}, nil
+}
@@ -0,0 +1,118 @@
+// Package gitlabmcp provides the GitLab MCP tool functionality.
+package gitlabmcp
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/ondratuma/gitlab-review-mcp/pkg/gitlab"
+)
+
+// Resolution states accepted by the state filter.
+const (
+	StateAll        = "all"
+	StateResolved   = "resolved"
+	StateUnresolved = "unresolved"
+)
+
+// CommentFilter selects the discussion threads returned by the comments tool.
+// The discussions API has no server-side filters, so all of them are applied
+// to the fetched threads.
+type CommentFilter struct {
+	State          string
+	Authors        []string
+	ExcludeAuthors []string
+	Since          time.Time
+	PathGlob       string
+}
+
+// ParseCommentFilter reads the filter parameters of a tool request.
+func ParseCommentFilter(request mcp.CallToolRequest) (CommentFilter, error) {
+	filter := CommentFilter{
+		State:          request.GetString("state", StateAll),
+		Authors:        request.GetStringSlice("authors", nil),
+		ExcludeAuthors: request.GetStringSlice("excludeAuthors", nil),
+		PathGlob:       request.GetString("pathGlob", ""),
+	}
+
+	switch filter.State {
+	case StateAll, StateResolved, StateUnresolved:
+	default:
+		return filter, fmt.Errorf("invalid state %q, expected one of %s, %s or %s", filter.State, StateUnresolved, StateResolved, StateAll)
+	}
+
+	if since := request.GetString("since", ""); since != "" {
+		parsed, err := parseSince(since)
+		if err != nil {
+			return filter, err
+		}
+		filter.Since = parsed
+	}
+
+	return filter, nil
+}
+
+// parseSince accepts either an RFC 3339 timestamp or a YYYY-MM-DD date.
+func parseSince(since string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, since); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse(time.DateOnly, since); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("invalid since %q, expected a date like 2024-01-31 or an RFC 3339 timestamp", since)
+}
+
+// Matches reports whether the discussion thread passes the filter.
+func (f CommentFilter) Matches(discussion gitlab.Discussion) (bool, error) {
+	if len(discussion.Notes) == 0 {
+		return false, nil
+	}
+
+	switch f.State {
+	case StateResolved:
+		if !discussion.IsResolved() {
+			return false, nil
+		}
+	case StateUnresolved:
+		if !discussion.IsResolvable() || discussion.IsResolved() {
+			return false, nil
+		}
+	}
+
+	// Author filters apply to whoever started the thread
+	author := discussion.Notes[0].Author.Username
+	if len(f.Authors) > 0 && !slices.Contains(f.Authors, author) {
+		return false, nil
+	}
+	if slices.Contains(f.ExcludeAuthors, author) {
+		return false, nil
+	}
+
+	// Keep threads with any activity since the given time
+	if !f.Since.IsZero() {
+		active := false
+		for _, note := range discussion.Notes {
+			created, err := time.Parse(time.RFC3339, note.CreatedAt)
+			if err == nil && !created.Before(f.Since) {
+				active = true
+				break
+			}
+		}
+		if !active {
+			return false, nil
+		}
+	}
+
+	if f.PathGlob != "" {
+		position := discussion.Notes[0].Position
+		if position == nil {
+			return false, nil
+		}
+		return MatchPathGlob(f.PathGlob, position.FilePath())
+	}
+
+	return true, nil
+}
@@ -0,0 +1,120 @@
+package gitlabmcp
+
+import (
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/ondratuma/gitlab-review-mcp/pkg/gitlab"
+)
+
+// TestCommentFilterMatches tests the CommentFilter.Matches method
+func TestCommentFilterMatches(t *testing.T) {
+	path := "pkg/gitlab/client.go"
+
+	newDiscussion := func(author, createdAt string, resolvable, resolved bool) gitlab.Discussion {
+		note := gitlab.MergeRequestNote{
+			ID:         1,
+			CreatedAt:  createdAt,
+			Resolvable: resolvable,
+			Resolved:   resolved,
+			Position:   &gitlab.NotePosition{NewPath: &path},
+		}
+		note.Author.Username = author
+		return gitlab.Discussion{ID: "abc", Notes: []gitlab.MergeRequestNote{note}}
+	}
+
+	unresolved := newDiscussion("alice", "2024-03-01T10:00:00.000Z", true, false)
+	resolved := newDiscussion("bob", "2024-01-01T10:00:00.000Z", true, true)
+
+	// Test cases
+	tests := []struct {
+		name       string
+		arguments  map[string]any
+		discussion gitlab.Discussion
+		expected   bool
+	}{
+		{
+			name:       "no filters",
+			arguments:  map[string]any{},
+			discussion: resolved,
+			expected:   true,
+		},
+		{
+			name:       "unresolved state excludes resolved threads",
+			arguments:  map[string]any{"state": "unresolved"},
+			discussion: resolved,
+			expected:   false,
+		},
+		{
+			name:       "unresolved state keeps open threads",
+			arguments:  map[string]any{"state": "unresolved"},
+			discussion: unresolved,
+			expected:   true,
+		},
+		{
+			name:       "authors",
+			arguments:  map[string]any{"authors": []any{"bob"}},
+			discussion: unresolved,
+			expected:   false,
+		},
+		{
+			name:       "exclude authors",
+			arguments:  map[string]any{"excludeAuthors": []any{"alice"}},
+			discussion: unresolved,
+			expected:   false,
+		},
+		{
+			name:       "since date",
+			arguments:  map[string]any{"since": "2024-02-01"},
+			discussion: resolved,
+			expected:   false,
+		},
+		{
+			name:       "since timestamp",
+			arguments:  map[string]any{"since": "2024-03-01T09:00:00Z"},
+			discussion: unresolved,
+			expected:   true,
+		},
+		{
+			name:       "path glob",
+			arguments:  map[string]any{"pathGlob": "cmd/**"},
+			discussion: unresolved,
+			expected:   false,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			request := mcp.CallToolRequest{}
+			request.Params.Arguments = tc.arguments
+
+			filter, err := ParseCommentFilter(request)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			matches, err := filter.Matches(tc.discussion)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if matches != tc.expected {
+				t.Errorf("expected %t, got %t", tc.expected, matches)
+			}
+		})
+	}
+}
+
+// TestParseCommentFilterInvalid tests that invalid filter values are rejected
+func TestParseCommentFilterInvalid(t *testing.T) {
+	for _, arguments := range []map[string]any{
+		{"state": "open"},
+		{"since": "last week"},
+	} {
+		request := mcp.CallToolRequest{}
+		request.Params.Arguments = arguments
+
+		if _, err := ParseCommentFilter(request); err == nil {
+			t.Errorf("expected error for %v but got nil", arguments)
+		}
+	}
+}
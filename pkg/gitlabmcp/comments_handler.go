@@ -7,118 +7,278 @@ import (
 	"strings"
 
 	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/ondratuma/gitlab-review-mcp/pkg/git"
 	"github.com/ondratuma/gitlab-review-mcp/pkg/gitlab"
 )
 
-func GetGroupedCommentThreads(comments []gitlab.MergeRequestNote) (map[string]map[string][]gitlab.MergeRequestNote, error) {
-	// Group comments by file path and line code
-	groupedComments := make(map[string]map[string][]gitlab.MergeRequestNote)
-	for _, comment := range comments {
-		if comment.System {
+// DiscussionsByFile holds the discussion threads anchored to a single file.
+type DiscussionsByFile struct {
+	Path        string
+	Discussions []gitlab.Discussion
+}
+
+// GetGroupedDiscussions groups positioned discussion threads by file path,
+// keeping the order in which GitLab returned them.
+func GetGroupedDiscussions(discussions []gitlab.Discussion) []DiscussionsByFile {
+	var grouped []DiscussionsByFile
+	indexByPath := make(map[string]int)
+
+	for _, discussion := range discussions {
+		if len(discussion.Notes) == 0 {
 			continue
 		}
 
-		// Skip comments without position
-		if comment.Position == nil {
+		// The first note carries the position of the whole thread
+		first := discussion.Notes[0]
+		if first.System {
+			continue
+		}
+
+		// Skip threads without position
+		if first.Position == nil {
 			continue
 		}
 
 		// Get the file path
-		path := "Unknown"
-		if comment.Position.NewPath != nil {
-			path = *comment.Position.NewPath
+		path := first.Position.FilePath()
+
+		idx, ok := indexByPath[path]
+		if !ok {
+			idx = len(grouped)
+			indexByPath[path] = idx
+			grouped = append(grouped, DiscussionsByFile{Path: path})
 		}
+		grouped[idx].Discussions = append(grouped[idx].Discussions, discussion)
+	}
 
-		// Get the line code identifier
-		lineCode := "general"
-		if comment.Position.LineRange != nil &&
-			comment.Position.LineRange.Start != nil &&
-			comment.Position.LineRange.Start.LineCode != "" {
-			lineCode = comment.Position.LineRange.Start.LineCode
-		} else if comment.Position.NewLine != nil {
-			// Fallback to line number if line_code is not available
-			lineCode = fmt.Sprintf("line_%d", *comment.Position.NewLine)
+	return grouped
+}
+
+// CommentOptions controls which discussion threads are returned and how.
+type CommentOptions struct {
+	ContextLines   int
+	IncludeGeneral bool
+	Filter         CommentFilter
+	// RepoPath is the local repository commented lines are mapped onto.
+	RepoPath string
+}
+
+// GetGeneralDiscussions returns the discussion threads that are not anchored
+// to the diff, such as comments on the overview tab.
+func GetGeneralDiscussions(discussions []gitlab.Discussion) []gitlab.Discussion {
+	var general []gitlab.Discussion
+	for _, discussion := range discussions {
+		if len(discussion.Notes) == 0 {
+			continue
 		}
 
-		// Initialize maps if needed
-		if _, ok := groupedComments[path]; !ok {
-			groupedComments[path] = make(map[string][]gitlab.MergeRequestNote)
+		first := discussion.Notes[0]
+		if first.System || first.Position != nil {
+			continue
 		}
 
-		// Add comment to the appropriate group
-		groupedComments[path][lineCode] = append(groupedComments[path][lineCode], comment)
+		general = append(general, discussion)
 	}
 
-	return groupedComments, nil
+	return general
 }
 
+// GetCommentsForMergeRequest formats the positioned discussion threads of a
+// merge request per file, showing code around each commented line.
 func GetCommentsForMergeRequest(
-	mr int,
+	ctx context.Context,
+	mr MergeRequestTarget,
+	options CommentOptions,
 	client *gitlab.Client,
-	config Config,
 ) ([]string, error) {
-	comments, err := client.GetMergeRequestComments(config.ProjectID, mr)
+	allDiscussions, err := client.GetMergeRequestDiscussions(ctx, mr.ProjectID, mr.IID)
 	if err != nil {
 		return []string{}, err
 	}
 
-	threadsByFiles, err := GetGroupedCommentThreads(comments)
-	if err != nil {
-		return []string{}, err
+	var discussions []gitlab.Discussion
+	for _, discussion := range allDiscussions {
+		ok, err := options.Filter.Matches(discussion)
+		if err != nil {
+			return []string{}, err
+		}
+		if ok {
+			discussions = append(discussions, discussion)
+		}
 	}
 
 	outputThreads := []string{}
+	diffs := newDiffSource(client, mr.ProjectID, mr.IID)
+	localDiffs := make(map[string]localDiff)
 
-	// Format grouped comments
-	for path, threadsByLine := range threadsByFiles {
+	// Format grouped discussions
+	for _, file := range GetGroupedDiscussions(discussions) {
 		var sb strings.Builder
-		sb.WriteString(fmt.Sprintf("\nFile: %s\n", path))
-
-		// Process each line code group
-		for _, comments := range threadsByLine {
-			if len(comments) > 0 {
-				comment := comments[0]
-				if comment.Position != nil && comment.Position.NewLine != nil {
-					sb.WriteString(fmt.Sprintf("Line: %s\n", comment.Position.NewLine))
+		sb.WriteString(fmt.Sprintf("\nFile: %s\n", file.Path))
+
+		for _, discussion := range file.Discussions {
+			sb.WriteString(fmt.Sprintf("Discussion: %s\n", discussion.ID))
+
+			first := discussion.Notes[0]
+			position := first.Position
+			if start, end, ok := PositionLines(position); ok {
+				if start == end {
+					sb.WriteString(fmt.Sprintf("Line: %s\n", start))
+				} else {
+					sb.WriteString(fmt.Sprintf("Lines: %s-%s\n", start, end))
 				}
+				sb.WriteString(formatCurrentLine(options.RepoPath, localDiffs, position, start))
+				sb.WriteString(formatThreadSnippet(ctx, diffs, position, start, end, options.ContextLines))
+			} else if position.IsImage() && position.X != nil && position.Y != nil {
+				sb.WriteString(fmt.Sprintf("Image position: x=%d, y=%d", *position.X, *position.Y))
+				if position.Width != nil && position.Height != nil {
+					sb.WriteString(fmt.Sprintf(" on a %dx%d image", *position.Width, *position.Height))
+				}
+				sb.WriteString("\n")
+			}
 
-				sb.WriteString(fmt.Sprintf("Resolved: %t\n", comment.Resolved))
+			writeThreadNotes(&sb, discussion)
+		}
+
+		outputThreads = append(outputThreads, sb.String())
+	}
+
+	// General discussions go last, in a section of their own
+	if options.IncludeGeneral {
+		if general := GetGeneralDiscussions(discussions); len(general) > 0 {
+			var sb strings.Builder
+			sb.WriteString("\nGeneral discussion\n")
+			for _, discussion := range general {
+				sb.WriteString(fmt.Sprintf("Discussion: %s\n", discussion.ID))
+				writeThreadNotes(&sb, discussion)
 			}
+			outputThreads = append(outputThreads, sb.String())
+		}
+	}
+
+	return outputThreads, nil
+}
 
-			// Write line information once per line code group
+// writeThreadNotes writes the resolution state and all comments of a thread.
+func writeThreadNotes(sb *strings.Builder, discussion gitlab.Discussion) {
+	if discussion.IsResolvable() {
+		sb.WriteString(fmt.Sprintf("Resolved: %t\n", discussion.IsResolved()))
+	}
 
-			// Write all comments for this line code
-			for _, comment := range comments {
-				sb.WriteString(fmt.Sprintf("[%s] Comment by %s\n", comment.CreatedAt, comment.Author.Username))
-				sb.WriteString(fmt.Sprintf("%s\n\n", comment.Body))
+	for _, comment := range discussion.Notes {
+		if comment.System {
+			continue
+		}
+		sb.WriteString(fmt.Sprintf("[%s] Comment by %s\n", comment.CreatedAt, comment.Author.Username))
+		sb.WriteString(fmt.Sprintf("%s\n", comment.Body))
+
+		for _, suggestion := range comment.Suggestions {
+			state := "not appliable"
+			switch {
+			case suggestion.Applied:
+				state = "applied"
+			case suggestion.Appliable:
+				state = "appliable"
 			}
+			sb.WriteString(fmt.Sprintf("Suggestion %d (lines %d-%d): %s\n", suggestion.ID, suggestion.FromLine, suggestion.ToLine, state))
 		}
+		sb.WriteString("\n")
+	}
+}
 
-		outputThreads = append(outputThreads, sb.String())
+// localDiff caches the diff between a commit and the working tree.
+type localDiff struct {
+	diff string
+	err  error
+}
+
+// formatCurrentLine reports where the commented line is in the working tree of
+// the repository at dir.
+func formatCurrentLine(dir string, localDiffs map[string]localDiff, position *gitlab.NotePosition, line LineRef) string {
+	// Removed lines no longer exist at the reviewed commit
+	if position.HeadSHA == "" || line.NewLine == 0 {
+		return ""
 	}
+	path := position.FilePath()
 
-	return outputThreads, nil
+	cached, ok := localDiffs[position.HeadSHA]
+	if !ok {
+		cached.diff, cached.err = git.DiffWorkingTree(dir, position.HeadSHA)
+		localDiffs[position.HeadSHA] = cached
+	}
+	if cached.err != nil {
+		return fmt.Sprintf("Current line: unknown (%s)\n", cached.err)
+	}
+
+	mapping, err := git.MapLine(cached.diff, path, line.NewLine)
+	if err != nil {
+		return fmt.Sprintf("Current line: unknown (%s)\n", err)
+	}
+
+	switch {
+	case mapping.Deleted:
+		return "Current line: line deleted in the working tree\n"
+	case mapping.Path != path:
+		return fmt.Sprintf("Current line: %s:%d (renamed)\n", mapping.Path, mapping.Line)
+	default:
+		return fmt.Sprintf("Current line: %d\n", mapping.Line)
+	}
+}
+
+// formatThreadSnippet formats the code around the commented line as the reviewer saw it.
+func formatThreadSnippet(ctx context.Context, diffs *diffSource, position *gitlab.NotePosition, start, end LineRef, contextLines int) string {
+	if position.HeadSHA == "" {
+		return ""
+	}
+
+	diff, err := diffs.FileDiff(ctx, position.HeadSHA, position.FilePath())
+	if err != nil {
+		return fmt.Sprintf("Code: unavailable (%s)\n", err)
+	}
+
+	snippet, err := CodeSnippet(diff.Diff, start, end, contextLines)
+	if err != nil {
+		return fmt.Sprintf("Code: unavailable (%s)\n", err)
+	}
+
+	return fmt.Sprintf("Code at %s:\n%s", position.HeadSHA, snippet)
 }
 
 // GetMergeRequestCommentsHandler handles the getMergeRequestComments tool request.
-func GetMergeRequestCommentsHandler(ctx context.Context, request mcp.CallToolRequest, config Config) (*mcp.CallToolResult, error) {
-	mergeRequestId := request.GetInt("mergeRequestIID", -1)
-	if mergeRequestId == -1 {
-		return mcp.NewToolResultError("Merge request ID is required"), nil
+func GetMergeRequestCommentsHandler(ctx context.Context, request mcp.CallToolRequest, client *gitlab.Client, config Config) (*mcp.CallToolResult, error) {
+	contextLines := request.GetInt("contextLines", DefaultContextLines)
+	if contextLines < 0 {
+		return mcp.NewToolResultError("contextLines must not be negative"), nil
+	}
+
+	filter, err := ParseCommentFilter(request)
+	if err != nil {
+		return toolError(err), nil
+	}
+
+	options := CommentOptions{
+		ContextLines:   contextLines,
+		IncludeGeneral: request.GetBool("includeGeneral", true),
+		Filter:         filter,
+		RepoPath:       repoPath(request, config),
+	}
+
+	mr, err := ResolveMergeRequest(ctx, request, client, config)
+	if err != nil {
+		return toolError(err), nil
 	}
 
-	client := gitlab.NewClient(config.GitLabToken)
 	contents := []mcp.Content{}
 
 	// Add each MR's comments as a separate content item
-	threadsForMr, err := GetCommentsForMergeRequest(mergeRequestId, client, config)
+	threadsForMr, err := GetCommentsForMergeRequest(ctx, mr, options, client)
 	if err != nil {
-		return mcp.NewToolResultError(err.Error()), nil
+		return toolError(err), nil
 	}
 
 	contents = append(contents, mcp.TextContent{
 		Type: "text",
-		Text: fmt.Sprintf("Comments for Merge Request:"),
+		Text: fmt.Sprintf("Comments for Merge Request !%d:", mr.IID),
 	})
 
 	for _, thread := range threadsForMr {
@@ -0,0 +1,69 @@
+package gitlabmcp
+
+import (
+	"testing"
+
+	"github.com/ondratuma/gitlab-review-mcp/pkg/gitlab"
+)
+
+// TestGetGroupedDiscussions tests the GetGroupedDiscussions function
+func TestGetGroupedDiscussions(t *testing.T) {
+	path := "main.go"
+	otherPath := "other.go"
+	line := 10
+
+	positioned := func(id, path string) gitlab.Discussion {
+		return gitlab.Discussion{
+			ID: id,
+			Notes: []gitlab.MergeRequestNote{
+				{ID: 1, Body: "comment", Position: &gitlab.NotePosition{NewPath: &path, NewLine: &line}},
+			},
+		}
+	}
+
+	discussions := []gitlab.Discussion{
+		positioned("first", path),
+		positioned("second", otherPath),
+		// Two separate threads on the same line must stay separate
+		positioned("third", path),
+		{ID: "general", IndividualNote: true, Notes: []gitlab.MergeRequestNote{{ID: 2, Body: "general"}}},
+		{ID: "system", IndividualNote: true, Notes: []gitlab.MergeRequestNote{{ID: 3, System: true}}},
+		{ID: "empty"},
+	}
+
+	grouped := GetGroupedDiscussions(discussions)
+
+	if len(grouped) != 2 {
+		t.Fatalf("expected 2 files, got %d", len(grouped))
+	}
+	if grouped[0].Path != path || grouped[1].Path != otherPath {
+		t.Errorf("expected files in order [%s %s], got [%s %s]", path, otherPath, grouped[0].Path, grouped[1].Path)
+	}
+	if len(grouped[0].Discussions) != 2 {
+		t.Fatalf("expected 2 discussions for %s, got %d", path, len(grouped[0].Discussions))
+	}
+	if grouped[0].Discussions[0].ID != "first" || grouped[0].Discussions[1].ID != "third" {
+		t.Errorf("unexpected discussions for %s: %+v", path, grouped[0].Discussions)
+	}
+}
+
+// TestGetGeneralDiscussions tests the GetGeneralDiscussions function
+func TestGetGeneralDiscussions(t *testing.T) {
+	path := "main.go"
+
+	discussions := []gitlab.Discussion{
+		{ID: "positioned", Notes: []gitlab.MergeRequestNote{{ID: 1, Position: &gitlab.NotePosition{NewPath: &path}}}},
+		{ID: "general", Notes: []gitlab.MergeRequestNote{{ID: 2, Body: "please add tests", Resolvable: true}, {ID: 3, Body: "done"}}},
+		{ID: "system", IndividualNote: true, Notes: []gitlab.MergeRequestNote{{ID: 4, System: true}}},
+		{ID: "empty"},
+	}
+
+	general := GetGeneralDiscussions(discussions)
+
+	if len(general) != 1 {
+		t.Fatalf("expected 1 general discussion, got %d", len(general))
+	}
+	if general[0].ID != "general" || len(general[0].Notes) != 2 {
+		t.Errorf("unexpected general discussion: %+v", general[0])
+	}
+}
@@ -1,8 +1,196 @@
 // Package gitlabmcp provides the GitLab MCP tool functionality.
 package gitlabmcp
 
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/ondratuma/gitlab-review-mcp/pkg/git"
+	"github.com/ondratuma/gitlab-review-mcp/pkg/gitlab"
+)
+
+// DefaultGitLabURL is used when no GitLab instance URL is configured or detected.
+const DefaultGitLabURL = "https://gitlab.com"
+
+// DefaultGitRemote is the git remote used to detect the GitLab project.
+const DefaultGitRemote = "origin"
+
+// Default timeouts of GitLab API requests and of whole tool calls.
+const (
+	DefaultRequestTimeout = 30 * time.Second
+	DefaultToolTimeout    = 2 * time.Minute
+)
+
 // Config holds the application configuration.
 type Config struct {
 	GitLabToken string
-	ProjectID   string
+
+	// ProjectID is the numeric ID or the full path of the project, e.g. group/subgroup/project.
+	// When empty, it is derived from the git remote.
+	ProjectID string
+
+	// Projects are further projects, by ID or path, searched for the merge
+	// requests of a branch next to ProjectID.
+	Projects []string
+
+	// Group is the ID or path of a group whose projects are searched for the
+	// merge requests of a branch.
+	Group string
+
+	// GitRemote is the name of the git remote used for auto-detection.
+	GitRemote string
+
+	// RepoPath is the directory of the local git repository. When empty, the
+	// working directory of the process is used. Tools can override it with
+	// their repoPath argument.
+	RepoPath string
+
+	// GitLabURL is the base URL of the GitLab instance, e.g. https://gitlab.example.com.
+	// When empty, it is derived from the git remote.
+	GitLabURL string
+
+	// TLS settings for self-managed instances
+	CACertPath         string
+	ClientCertPath     string
+	ClientKeyPath      string
+	InsecureSkipVerify bool
+
+	// RequestTimeout limits every GitLab API request including its retries,
+	// zero for no limit.
+	RequestTimeout time.Duration
+
+	// ToolTimeout limits every tool call, zero for no limit.
+	ToolTimeout time.Duration
+
+	// MaxListItems caps the items read from every GitLab list, such as the
+	// discussions of a merge request, zero for no limit.
+	MaxListItems int
+}
+
+// NewDefaultConfig creates a configuration for the given token and project.
+func NewDefaultConfig(gitlabToken, projectID string) Config {
+	return Config{
+		GitLabToken:    gitlabToken,
+		ProjectID:      projectID,
+		GitRemote:      DefaultGitRemote,
+		RequestTimeout: DefaultRequestTimeout,
+		ToolTimeout:    DefaultToolTimeout,
+	}
+}
+
+// DetectGitLabURL derives the GitLab instance URL from the given git remote of
+// the repository at repoPath, falling back to DefaultGitLabURL.
+func DetectGitLabURL(repoPath, remoteName string) string {
+	remote, err := getRemote(repoPath, remoteName)
+	if err != nil {
+		return DefaultGitLabURL
+	}
+
+	return remote.WebURL()
+}
+
+// detectGitLabInstance derives the GitLab instance from the git remote like
+// DetectGitLabURL. The token must not reach other hosts, so remotes outside
+// gitlab.com are only used when the instance answers like GitLab.
+func detectGitLabInstance(ctx context.Context, config Config) (string, error) {
+	gitLabURL := DetectGitLabURL(config.RepoPath, config.GitRemote)
+	if u, err := url.Parse(gitLabURL); err == nil && u.Scheme == "https" && u.Host == "gitlab.com" {
+		return gitLabURL, nil
+	}
+
+	config.GitLabURL = gitLabURL
+	client, err := newGitLabClient(config)
+	if err != nil {
+		return "", err
+	}
+
+	isGitLab, err := client.IsGitLab(ctx)
+	if err != nil {
+		return "", fmt.Errorf("checking whether the git remote %q points to a GitLab instance at %s, set GITLAB_URL: %w", config.GitRemote, gitLabURL, err)
+	}
+	if !isGitLab {
+		return "", fmt.Errorf("the git remote %q points to %s, which is not a GitLab instance, set GITLAB_URL", config.GitRemote, gitLabURL)
+	}
+
+	return gitLabURL, nil
+}
+
+// DetectProjectID derives the project path from the given git remote of the
+// repository at repoPath. The path of gitLabURL is stripped for instances
+// served under a relative URL.
+func DetectProjectID(repoPath, remoteName, gitLabURL string) (string, error) {
+	remote, err := getRemote(repoPath, remoteName)
+	if err != nil {
+		return "", err
+	}
+
+	projectPath := remote.Path
+	if u, err := url.Parse(gitLabURL); err == nil && remote.Scheme == u.Scheme {
+		prefix := strings.Trim(u.Path, "/")
+		if prefix != "" {
+			projectPath = strings.TrimPrefix(projectPath, prefix+"/")
+		}
+	}
+
+	if !strings.Contains(projectPath, "/") {
+		return "", fmt.Errorf("git remote %q does not point to a GitLab project: %s", remoteName, remote.Path)
+	}
+
+	return projectPath, nil
+}
+
+// getRemote reads and parses the URL of the given git remote.
+func getRemote(repoPath, remoteName string) (*git.RemoteURL, error) {
+	if remoteName == "" {
+		remoteName = DefaultGitRemote
+	}
+
+	rawURL, err := git.GetRemoteURL(repoPath, remoteName)
+	if err != nil {
+		return nil, fmt.Errorf("reading git remote %q: %w", remoteName, err)
+	}
+
+	return git.ParseRemoteURL(rawURL)
+}
+
+// newGitLabClient creates a GitLab API client for the configured instance.
+func newGitLabClient(config Config) (*gitlab.Client, error) {
+	client, err := gitlab.NewClientWithOptions(config.GitLabURL, config.GitLabToken, gitlab.TLSOptions{
+		CACertPath:         config.CACertPath,
+		ClientCertPath:     config.ClientCertPath,
+		ClientKeyPath:      config.ClientKeyPath,
+		InsecureSkipVerify: config.InsecureSkipVerify,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	client.Timeout = config.RequestTimeout
+	client.MaxListItems = config.MaxListItems
+	return client, nil
+}
+
+// repoPath returns the local repository a tool request works on. The repoPath
+// argument may be a directory or a file:// URI, as handed out by MCP clients
+// for workspace roots, and relative paths are resolved against the configured
+// repository. Without the argument the configured repository is used.
+func repoPath(request mcp.CallToolRequest, config Config) string {
+	path := request.GetString("repoPath", "")
+	if path == "" {
+		return config.RepoPath
+	}
+
+	if u, err := url.Parse(path); err == nil && u.Scheme == "file" {
+		path = filepath.FromSlash(u.Path)
+	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(config.RepoPath, path)
+	}
+
+	return path
 }
@@ -1,7 +1,15 @@
 package gitlabmcp
 
 import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/ondratuma/gitlab-review-mcp/pkg/git"
 )
 
 // TestNewDefaultConfig tests the NewDefaultConfig function
@@ -43,4 +51,189 @@ func TestNewDefaultConfig(t *testing.T) {
 			}
 		})
 	}
-}
\ No newline at end of file
+}
+
+// TestDetectProjectID tests the DetectProjectID function
+func TestDetectProjectID(t *testing.T) {
+	// Save the original GetRemoteURL function and restore it after the test
+	originalGetRemoteURL := git.GetRemoteURL
+	defer func() { git.GetRemoteURL = originalGetRemoteURL }()
+
+	// Test cases
+	tests := []struct {
+		name              string
+		remoteURL         string
+		remoteError       error
+		gitLabURL         string
+		expectedProjectID string
+		expectError       bool
+	}{
+		{
+			name:              "scp-style remote with subgroups",
+			remoteURL:         "git@gitlab.com:group/subgroup/project.git",
+			gitLabURL:         "https://gitlab.com",
+			expectedProjectID: "group/subgroup/project",
+		},
+		{
+			name:              "https remote with custom port",
+			remoteURL:         "https://gitlab.example.com:8443/group/project.git",
+			gitLabURL:         "https://gitlab.example.com:8443",
+			expectedProjectID: "group/project",
+		},
+		{
+			name:              "instance under relative URL",
+			remoteURL:         "https://example.com/gitlab/group/project.git",
+			gitLabURL:         "https://example.com/gitlab",
+			expectedProjectID: "group/project",
+		},
+		{
+			name:        "missing remote",
+			remoteError: errors.New("No such remote 'origin'"),
+			expectError: true,
+		},
+		{
+			name:        "not a project path",
+			remoteURL:   "https://gitlab.com/project.git",
+			gitLabURL:   "https://gitlab.com",
+			expectError: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			// Mock the git.GetRemoteURL function
+			git.GetRemoteURL = func(dir, remote string) (string, error) {
+				if remote != DefaultGitRemote {
+					t.Errorf("expected remote %q, got %q", DefaultGitRemote, remote)
+				}
+				return tc.remoteURL, tc.remoteError
+			}
+
+			// Call the function
+			projectID, err := DetectProjectID("", DefaultGitRemote, tc.gitLabURL)
+
+			// Check the results
+			if tc.expectError {
+				if err == nil {
+					t.Errorf("expected error but got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if projectID != tc.expectedProjectID {
+				t.Errorf("expected project ID %q, got %q", tc.expectedProjectID, projectID)
+			}
+		})
+	}
+}
+
+// TestDetectGitLabInstance tests that remotes outside gitlab.com are only
+// used when they point to a GitLab instance
+func TestDetectGitLabInstance(t *testing.T) {
+	// Save the original GetRemoteURL function and restore it after the test
+	originalGetRemoteURL := git.GetRemoteURL
+	defer func() { git.GetRemoteURL = originalGetRemoteURL }()
+
+	gitLab := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("PRIVATE-TOKEN") != "" {
+			t.Error("expected the token not to be sent")
+		}
+		w.WriteHeader(http.StatusUnauthorized)
+		_, _ = w.Write([]byte(`{"message":"401 Unauthorized"}`))
+	}))
+	defer gitLab.Close()
+
+	otherForge := httptest.NewServer(http.NotFoundHandler())
+	defer otherForge.Close()
+
+	tests := []struct {
+		name        string
+		remoteURL   string
+		expectedURL string
+		expectedErr string
+	}{
+		{
+			name:        "gitlab.com",
+			remoteURL:   "git@gitlab.com:group/project.git",
+			expectedURL: "https://gitlab.com",
+		},
+		{
+			name:        "self-managed GitLab",
+			remoteURL:   gitLab.URL + "/group/project.git",
+			expectedURL: gitLab.URL,
+		},
+		{
+			name:        "other forge",
+			remoteURL:   otherForge.URL + "/group/project.git",
+			expectedErr: "which is not a GitLab instance, set GITLAB_URL",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			git.GetRemoteURL = func(dir, remote string) (string, error) {
+				return tc.remoteURL, nil
+			}
+
+			gitLabURL, err := detectGitLabInstance(context.Background(), NewDefaultConfig("test-token", ""))
+			if tc.expectedErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.expectedErr) {
+					t.Errorf("expected error containing %q, got %v", tc.expectedErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if gitLabURL != tc.expectedURL {
+				t.Errorf("expected %q, got %q", tc.expectedURL, gitLabURL)
+			}
+		})
+	}
+}
+
+// TestRepoPath tests the repoPath function
+func TestRepoPath(t *testing.T) {
+	tests := []struct {
+		name     string
+		argument any
+		repoPath string
+		expected string
+	}{
+		{name: "configured repository", repoPath: "/work/app", expected: "/work/app"},
+		{name: "process working directory", expected: ""},
+		{name: "absolute path", argument: "/work/lib", repoPath: "/work/app", expected: "/work/lib"},
+		{name: "relative path", argument: "../lib", repoPath: "/work/app", expected: "/work/lib"},
+		{name: "file URI", argument: "file:///work/my%20lib", repoPath: "/work/app", expected: "/work/my lib"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			request := mcp.CallToolRequest{}
+			if tc.argument != nil {
+				request.Params.Arguments = map[string]any{"repoPath": tc.argument}
+			}
+
+			actual := repoPath(request, Config{RepoPath: tc.repoPath})
+			if actual != tc.expected {
+				t.Errorf("expected %q, got %q", tc.expected, actual)
+			}
+		})
+	}
+}
+
+// TestNewGitLabClient tests that the client takes over the request settings
+func TestNewGitLabClient(t *testing.T) {
+	config := NewDefaultConfig("test-token", "12345")
+	config.MaxListItems = 500
+
+	client, err := newGitLabClient(config)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if client.Timeout != DefaultRequestTimeout || client.MaxListItems != 500 {
+		t.Errorf("unexpected client settings: timeout %v, max list items %d", client.Timeout, client.MaxListItems)
+	}
+}
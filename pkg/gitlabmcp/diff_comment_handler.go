@@ -0,0 +1,187 @@
+// Package gitlabmcp provides the GitLab MCP tool functionality.
+package gitlabmcp
+
+import (
+	"context"
+	"crypto/sha1"
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/ondratuma/gitlab-review-mcp/pkg/git"
+	"github.com/ondratuma/gitlab-review-mcp/pkg/gitlab"
+)
+
+// Diff sides a new comment can be attached to.
+const (
+	SideNew = "new"
+	SideOld = "old"
+)
+
+// BuildDiffPosition creates the position of a new diff comment on path in the
+// given merge request version. The comment covers line to endLine on the
+// given side; an endLine of 0 comments on a single line. It fails when the
+// lines are not part of the diff.
+func BuildDiffPosition(version gitlab.MergeRequestVersion, path, side string, line, endLine int) (*gitlab.NotePosition, error) {
+	if side != SideNew && side != SideOld {
+		return nil, fmt.Errorf("invalid side %q, expected %s or %s", side, SideNew, SideOld)
+	}
+	if endLine == 0 {
+		endLine = line
+	}
+	if endLine < line {
+		return nil, fmt.Errorf("endLine %d is before line %d", endLine, line)
+	}
+
+	var fileDiff *gitlab.MergeRequestDiff
+	for i := range version.Diffs {
+		if version.Diffs[i].NewPath == path || version.Diffs[i].OldPath == path {
+			fileDiff = &version.Diffs[i]
+			break
+		}
+	}
+	if fileDiff == nil {
+		return nil, fmt.Errorf("%s is not changed in the merge request", path)
+	}
+
+	hunks, err := git.ParseHunks(fileDiff.Diff)
+	if err != nil {
+		return nil, err
+	}
+
+	start, startHunk, ok := findDiffLine(hunks, side, line)
+	if !ok {
+		return nil, fmt.Errorf("line %d (%s) of %s is not part of the diff", line, side, path)
+	}
+	end, endHunk, ok := findDiffLine(hunks, side, endLine)
+	if !ok {
+		return nil, fmt.Errorf("line %d (%s) of %s is not part of the diff", endLine, side, path)
+	}
+	// Lines between hunks are not part of the diff, so GitLab cannot show such a range
+	if startHunk != endHunk {
+		return nil, fmt.Errorf("lines %d-%d (%s) of %s span several hunks of the diff, comment on each hunk separately", line, endLine, side, path)
+	}
+
+	position := &gitlab.NotePosition{
+		BaseSHA:      version.BaseCommitSHA,
+		StartSHA:     version.StartCommitSHA,
+		HeadSHA:      version.HeadCommitSHA,
+		PositionType: "text",
+		OldPath:      &fileDiff.OldPath,
+		NewPath:      &fileDiff.NewPath,
+	}
+
+	// GitLab anchors the note to the last line of the range
+	if end.OldLine != 0 {
+		position.OldLine = &end.OldLine
+	}
+	if end.NewLine != 0 {
+		position.NewLine = &end.NewLine
+	}
+
+	if endLine != line {
+		position.LineRange = &gitlab.LineRange{
+			Start: linePosition(fileDiff, start),
+			End:   linePosition(fileDiff, end),
+		}
+	}
+
+	return position, nil
+}
+
+// getLatestVersion retrieves the newest diff version of a merge request including its diffs.
+func getLatestVersion(ctx context.Context, client *gitlab.Client, projectID string, mrIID int) (*gitlab.MergeRequestVersion, error) {
+	versions, err := client.GetMergeRequestVersions(ctx, projectID, mrIID)
+	if err != nil {
+		return nil, err
+	}
+	if len(versions) == 0 {
+		return nil, fmt.Errorf("merge request !%d has no diff versions", mrIID)
+	}
+
+	return client.GetMergeRequestVersion(ctx, projectID, mrIID, versions[0].ID)
+}
+
+// findDiffLine finds the diff line with the given number on one side of the
+// diff, along with the index of its hunk.
+func findDiffLine(hunks []git.DiffHunk, side string, number int) (git.DiffLine, int, bool) {
+	for i, hunk := range hunks {
+		for _, line := range hunk.NumberedLines() {
+			if side == SideNew && line.NewLine == number {
+				return line, i, true
+			}
+			if side == SideOld && line.OldLine == number {
+				return line, i, true
+			}
+		}
+	}
+	return git.DiffLine{}, 0, false
+}
+
+// linePosition describes one end of a multi-line comment range. The line
+// code uses the running counters of both sides, like GitLab does, also for
+// added and removed lines.
+func linePosition(fileDiff *gitlab.MergeRequestDiff, line git.DiffLine) *gitlab.LinePosition {
+	position := &gitlab.LinePosition{
+		LineCode: fmt.Sprintf("%x_%d_%d", sha1.Sum([]byte(fileDiff.NewPath)), line.OldPos, line.NewPos),
+	}
+
+	switch line.Prefix {
+	case '+':
+		position.Type = SideNew
+	case '-':
+		position.Type = SideOld
+	}
+
+	if line.OldLine != 0 {
+		position.OldLine = &line.OldLine
+	}
+	if line.NewLine != 0 {
+		position.NewLine = &line.NewLine
+	}
+
+	return position
+}
+
+// CreateDiffCommentHandler handles the createDiffComment tool request.
+func CreateDiffCommentHandler(ctx context.Context, request mcp.CallToolRequest, client *gitlab.Client, config Config) (*mcp.CallToolResult, error) {
+	path, err := request.RequireString("path")
+	if err != nil {
+		return toolError(err), nil
+	}
+
+	line, err := request.RequireInt("line")
+	if err != nil {
+		return toolError(err), nil
+	}
+
+	body, err := request.RequireString("body")
+	if err != nil {
+		return toolError(err), nil
+	}
+
+	side := request.GetString("side", SideNew)
+	endLine := request.GetInt("endLine", 0)
+
+	mr, err := ResolveMergeRequest(ctx, request, client, config)
+	if err != nil {
+		return toolError(err), nil
+	}
+
+	// Comments are always placed on the latest version of the diff
+	version, err := getLatestVersion(ctx, client, mr.ProjectID, mr.IID)
+	if err != nil {
+		return toolError(err), nil
+	}
+
+	position, err := BuildDiffPosition(*version, path, side, line, endLine)
+	if err != nil {
+		return toolError(err), nil
+	}
+
+	discussion, err := client.CreateMergeRequestDiscussion(ctx, mr.ProjectID, mr.IID, body, position)
+	if err != nil {
+		return toolError(err), nil
+	}
+
+	return mcp.NewToolResultText(fmt.Sprintf("Created discussion %s on %s line %d of !%d", discussion.ID, path, line, mr.IID)), nil
+}
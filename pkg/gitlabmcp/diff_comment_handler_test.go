@@ -0,0 +1,211 @@
+package gitlabmcp
+
+import (
+	"crypto/sha1"
+	"fmt"
+	"testing"
+
+	"github.com/ondratuma/gitlab-review-mcp/pkg/gitlab"
+)
+
+// TestBuildDiffPosition tests the BuildDiffPosition function
+func TestBuildDiffPosition(t *testing.T) {
+	version := gitlab.MergeRequestVersion{
+		ID:             1,
+		HeadCommitSHA:  "head",
+		BaseCommitSHA:  "base",
+		StartCommitSHA: "start",
+		Diffs: []gitlab.MergeRequestDiff{
+			{
+				OldPath: "main.go",
+				NewPath: "main.go",
+				Diff: "@@ -1,4 +1,5 @@\n" +
+					" package main\n" +
+					" \n" +
+					"-import \"fmt\"\n" +
+					"+import (\n" +
+					"+\t\"fmt\"\n" +
+					"+)\n" +
+					" \n" +
+					"@@ -10,2 +11,2 @@\n" +
+					" func main() {\n" +
+					"-\tfmt.Println(\"a\")\n" +
+					"+\tfmt.Println(\"b\")\n",
+			},
+		},
+	}
+
+	// Test cases
+	tests := []struct {
+		name            string
+		path            string
+		side            string
+		line            int
+		endLine         int
+		expectedOldLine int
+		expectedNewLine int
+		expectRange     bool
+		// Type and old_new suffix of the line codes of the range ends
+		expectedStartType string
+		expectedStartCode string
+		expectedEndCode   string
+		expectError       bool
+	}{
+		{
+			name:            "added line",
+			path:            "main.go",
+			side:            SideNew,
+			line:            4,
+			expectedNewLine: 4,
+		},
+		{
+			name:            "unchanged line",
+			path:            "main.go",
+			side:            SideNew,
+			line:            1,
+			expectedOldLine: 1,
+			expectedNewLine: 1,
+		},
+		{
+			name:            "removed line",
+			path:            "main.go",
+			side:            SideOld,
+			line:            3,
+			expectedOldLine: 3,
+		},
+		{
+			name:              "multi-line range",
+			path:              "main.go",
+			side:              SideNew,
+			line:              3,
+			endLine:           5,
+			expectedNewLine:   5,
+			expectRange:       true,
+			expectedStartType: SideNew,
+			expectedStartCode: "4_3",
+			expectedEndCode:   "4_5",
+		},
+		{
+			name:              "range starting on a removed line",
+			path:              "main.go",
+			side:              SideOld,
+			line:              3,
+			endLine:           4,
+			expectedOldLine:   4,
+			expectedNewLine:   6,
+			expectRange:       true,
+			expectedStartType: SideOld,
+			expectedStartCode: "3_3",
+			expectedEndCode:   "4_6",
+		},
+		{
+			name:              "range ending on an added line",
+			path:              "main.go",
+			side:              SideNew,
+			line:              1,
+			endLine:           3,
+			expectedNewLine:   3,
+			expectRange:       true,
+			expectedStartCode: "1_1",
+			expectedEndCode:   "4_3",
+		},
+		{
+			name:              "range ending on a removed line",
+			path:              "main.go",
+			side:              SideOld,
+			line:              10,
+			endLine:           11,
+			expectedOldLine:   11,
+			expectRange:       true,
+			expectedStartCode: "10_11",
+			expectedEndCode:   "11_12",
+		},
+		{
+			name:        "range across hunks",
+			path:        "main.go",
+			side:        SideNew,
+			line:        5,
+			endLine:     12,
+			expectError: true,
+		},
+		{
+			name:        "line outside the diff",
+			path:        "main.go",
+			side:        SideNew,
+			line:        20,
+			expectError: true,
+		},
+		{
+			name:        "file not in the diff",
+			path:        "other.go",
+			side:        SideNew,
+			line:        1,
+			expectError: true,
+		},
+		{
+			name:        "invalid side",
+			path:        "main.go",
+			side:        "left",
+			line:        1,
+			expectError: true,
+		},
+		{
+			name:        "reversed range",
+			path:        "main.go",
+			side:        SideNew,
+			line:        5,
+			endLine:     3,
+			expectError: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			// Call the function
+			position, err := BuildDiffPosition(version, tc.path, tc.side, tc.line, tc.endLine)
+
+			// Check the results
+			if tc.expectError {
+				if err == nil {
+					t.Errorf("expected error but got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if position.HeadSHA != "head" || position.BaseSHA != "base" || position.StartSHA != "start" {
+				t.Errorf("unexpected SHAs: %+v", position)
+			}
+
+			oldLine, newLine := 0, 0
+			if position.OldLine != nil {
+				oldLine = *position.OldLine
+			}
+			if position.NewLine != nil {
+				newLine = *position.NewLine
+			}
+			if oldLine != tc.expectedOldLine || newLine != tc.expectedNewLine {
+				t.Errorf("expected old/new line %d/%d, got %d/%d", tc.expectedOldLine, tc.expectedNewLine, oldLine, newLine)
+			}
+
+			if tc.expectRange != (position.LineRange != nil) {
+				t.Fatalf("expected line range %t, got %+v", tc.expectRange, position.LineRange)
+			}
+			if !tc.expectRange {
+				return
+			}
+			if position.LineRange.Start.Type != tc.expectedStartType {
+				t.Errorf("expected range start type %q, got %q", tc.expectedStartType, position.LineRange.Start.Type)
+			}
+			prefix := fmt.Sprintf("%x_", sha1.Sum([]byte("main.go")))
+			if code := position.LineRange.Start.LineCode; code != prefix+tc.expectedStartCode {
+				t.Errorf("expected range start code %q, got %q", prefix+tc.expectedStartCode, code)
+			}
+			if code := position.LineRange.End.LineCode; code != prefix+tc.expectedEndCode {
+				t.Errorf("expected range end code %q, got %q", prefix+tc.expectedEndCode, code)
+			}
+		})
+	}
+}
@@ -0,0 +1,81 @@
+// Package gitlabmcp provides the GitLab MCP tool functionality.
+package gitlabmcp
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/ondratuma/gitlab-review-mcp/pkg/gitlab"
+)
+
+// ReplyToDiscussionHandler handles the replyToDiscussion tool request.
+func ReplyToDiscussionHandler(ctx context.Context, request mcp.CallToolRequest, client *gitlab.Client, config Config) (*mcp.CallToolResult, error) {
+	discussionID, err := request.RequireString("discussionID")
+	if err != nil {
+		return toolError(err), nil
+	}
+
+	body, err := request.RequireString("body")
+	if err != nil {
+		return toolError(err), nil
+	}
+
+	mr, err := ResolveMergeRequest(ctx, request, client, config)
+	if err != nil {
+		return toolError(err), nil
+	}
+	note, err := client.ReplyToDiscussion(ctx, mr.ProjectID, mr.IID, discussionID, body)
+	if err != nil {
+		return toolError(err), nil
+	}
+
+	return mcp.NewToolResultText(fmt.Sprintf("Replied to discussion %s on !%d (note %d)", discussionID, mr.IID, note.ID)), nil
+}
+
+// ResolveDiscussionHandler handles the resolveDiscussion tool request.
+func ResolveDiscussionHandler(ctx context.Context, request mcp.CallToolRequest, client *gitlab.Client, config Config) (*mcp.CallToolResult, error) {
+	discussionID, err := request.RequireString("discussionID")
+	if err != nil {
+		return toolError(err), nil
+	}
+
+	resolved := !request.GetBool("unresolve", false)
+	allowOthers := request.GetBool("allowOthers", false)
+
+	mr, err := ResolveMergeRequest(ctx, request, client, config)
+	if err != nil {
+		return toolError(err), nil
+	}
+
+	// Only resolve threads started by the token owner unless explicitly allowed
+	if resolved && !allowOthers {
+		discussion, err := client.GetMergeRequestDiscussion(ctx, mr.ProjectID, mr.IID, discussionID)
+		if err != nil {
+			return toolError(err), nil
+		}
+
+		user, err := client.GetCurrentUser(ctx)
+		if err != nil {
+			return toolError(err), nil
+		}
+
+		if len(discussion.Notes) > 0 && discussion.Notes[0].Author.Username != user.Username {
+			return mcp.NewToolResultError(fmt.Sprintf(
+				"Discussion %s was started by %s, not %s; set allowOthers to resolve it anyway",
+				discussionID, discussion.Notes[0].Author.Username, user.Username,
+			)), nil
+		}
+	}
+
+	if _, err := client.ResolveDiscussion(ctx, mr.ProjectID, mr.IID, discussionID, resolved); err != nil {
+		return toolError(err), nil
+	}
+
+	action := "Resolved"
+	if !resolved {
+		action = "Unresolved"
+	}
+
+	return mcp.NewToolResultText(fmt.Sprintf("%s discussion %s on !%d", action, discussionID, mr.IID)), nil
+}
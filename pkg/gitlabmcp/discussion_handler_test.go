@@ -0,0 +1,97 @@
+package gitlabmcp
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/ondratuma/gitlab-review-mcp/pkg/gitlab"
+)
+
+// TestResolveDiscussionHandler tests that only threads started by the token
+// owner are resolved unless allowOthers is set
+func TestResolveDiscussionHandler(t *testing.T) {
+	config := Config{ProjectID: "123"}
+
+	// The thread was started by alice, the token belongs to bob
+	bodies := map[string]string{
+		"/api/v4/projects/123/merge_requests/7/discussions/abc": `{"id":"abc","notes":[{"id":1,"author":{"username":"alice"}}]}`,
+		"/api/v4/user": `{"id":2,"username":"bob"}`,
+	}
+
+	tests := []struct {
+		name      string
+		arguments map[string]any
+		// expectedRequests are the requests sent, as "METHOD path"
+		expectedRequests []string
+		expectedQuery    string
+		expectedText     string
+		expectError      bool
+	}{
+		{
+			name:      "thread of another user is refused",
+			arguments: map[string]any{"mergeRequest": "!7", "discussionID": "abc"},
+			expectedRequests: []string{
+				"GET /api/v4/projects/123/merge_requests/7/discussions/abc",
+				"GET /api/v4/user",
+			},
+			expectedText: "Discussion abc was started by alice, not bob",
+			expectError:  true,
+		},
+		{
+			name:             "thread of another user is resolved with allowOthers",
+			arguments:        map[string]any{"mergeRequest": "!7", "discussionID": "abc", "allowOthers": true},
+			expectedRequests: []string{"PUT /api/v4/projects/123/merge_requests/7/discussions/abc"},
+			expectedQuery:    "resolved=true",
+			expectedText:     "Resolved discussion abc on !7",
+		},
+		{
+			name:             "unresolving skips the author check",
+			arguments:        map[string]any{"mergeRequest": "!7", "discussionID": "abc", "unresolve": true},
+			expectedRequests: []string{"PUT /api/v4/projects/123/merge_requests/7/discussions/abc"},
+			expectedQuery:    "resolved=false",
+			expectedText:     "Unresolved discussion abc on !7",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			httpClient := &mockHTTPClient{bodies: bodies}
+			client := gitlab.NewClient("test-token")
+			client.HTTPClient = httpClient
+
+			request := mcp.CallToolRequest{}
+			request.Params.Arguments = tc.arguments
+
+			result, err := ResolveDiscussionHandler(context.Background(), request, client, config)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if result.IsError != tc.expectError {
+				t.Errorf("expected error result %t, got %+v", tc.expectError, result)
+			}
+			text := result.Content[0].(mcp.TextContent).Text
+			if !strings.Contains(text, tc.expectedText) {
+				t.Errorf("expected result containing %q, got %q", tc.expectedText, text)
+			}
+
+			if len(httpClient.requests) != len(tc.expectedRequests) {
+				t.Fatalf("expected %d request(s), got %d", len(tc.expectedRequests), len(httpClient.requests))
+			}
+			for i, req := range httpClient.requests {
+				if got := req.Method + " " + req.URL.Path; got != tc.expectedRequests[i] {
+					t.Errorf("expected request %q, got %q", tc.expectedRequests[i], got)
+				}
+			}
+
+			if tc.expectedQuery != "" {
+				last := httpClient.requests[len(httpClient.requests)-1]
+				if last.URL.RawQuery != tc.expectedQuery {
+					t.Errorf("expected query %q, got %q", tc.expectedQuery, last.URL.RawQuery)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,185 @@
+// Package gitlabmcp provides the GitLab MCP tool functionality.
+package gitlabmcp
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/ondratuma/gitlab-review-mcp/pkg/gitlab"
+)
+
+// FormatDraftNote formats a single draft note for the tool output.
+func FormatDraftNote(note gitlab.DraftNote) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Draft note: %d\n", note.ID))
+
+	if note.Position != nil {
+		sb.WriteString(fmt.Sprintf("File: %s\n", note.Position.FilePath()))
+		if start, end, ok := PositionLines(note.Position); ok {
+			if start == end {
+				sb.WriteString(fmt.Sprintf("Line: %s\n", start))
+			} else {
+				sb.WriteString(fmt.Sprintf("Lines: %s-%s\n", start, end))
+			}
+		}
+	}
+	if note.DiscussionID != "" {
+		sb.WriteString(fmt.Sprintf("Reply to discussion: %s\n", note.DiscussionID))
+	}
+	if note.ResolveDiscussion {
+		sb.WriteString("Resolves the discussion when published\n")
+	}
+
+	sb.WriteString(note.Note)
+	sb.WriteString("\n")
+
+	return sb.String()
+}
+
+// CreateDraftNoteHandler handles the createDraftNote tool request.
+func CreateDraftNoteHandler(ctx context.Context, request mcp.CallToolRequest, client *gitlab.Client, config Config) (*mcp.CallToolResult, error) {
+	body, err := request.RequireString("body")
+	if err != nil {
+		return toolError(err), nil
+	}
+
+	opts := gitlab.CreateDraftNoteOptions{
+		Note:                  body,
+		InReplyToDiscussionID: request.GetString("discussionID", ""),
+		ResolveDiscussion:     request.GetBool("resolveDiscussion", false),
+	}
+
+	path := request.GetString("path", "")
+	line := request.GetInt("line", 0)
+	if path != "" && opts.InReplyToDiscussionID != "" {
+		return mcp.NewToolResultError("A draft note is either a reply to a discussion or an inline comment, not both"), nil
+	}
+	if (path == "") != (line == 0) {
+		return mcp.NewToolResultError("Inline draft notes need both a path and a line"), nil
+	}
+
+	mr, err := ResolveMergeRequest(ctx, request, client, config)
+	if err != nil {
+		return toolError(err), nil
+	}
+
+	// Inline draft notes are placed on the latest version of the diff
+	if path != "" {
+		version, err := getLatestVersion(ctx, client, mr.ProjectID, mr.IID)
+		if err != nil {
+			return toolError(err), nil
+		}
+
+		side := request.GetString("side", SideNew)
+		endLine := request.GetInt("endLine", 0)
+		opts.Position, err = BuildDiffPosition(*version, path, side, line, endLine)
+		if err != nil {
+			return toolError(err), nil
+		}
+	}
+
+	note, err := client.CreateDraftNote(ctx, mr.ProjectID, mr.IID, opts)
+	if err != nil {
+		return toolError(err), nil
+	}
+
+	return mcp.NewToolResultText(fmt.Sprintf("Created draft note %d on !%d", note.ID, mr.IID)), nil
+}
+
+// ListDraftNotesHandler handles the listDraftNotes tool request.
+func ListDraftNotesHandler(ctx context.Context, request mcp.CallToolRequest, client *gitlab.Client, config Config) (*mcp.CallToolResult, error) {
+	mr, err := ResolveMergeRequest(ctx, request, client, config)
+	if err != nil {
+		return toolError(err), nil
+	}
+
+	notes, err := client.GetDraftNotes(ctx, mr.ProjectID, mr.IID)
+	if err != nil {
+		return toolError(err), nil
+	}
+
+	contents := []mcp.Content{
+		mcp.TextContent{
+			Type: "text",
+			Text: fmt.Sprintf("Found %d draft note(s) on !%d", len(notes), mr.IID),
+		},
+	}
+	for _, note := range notes {
+		contents = append(contents, mcp.TextContent{
+			Type: "text",
+			Text: FormatDraftNote(note),
+		})
+	}
+
+	return &mcp.CallToolResult{
+		Content: contents,
+	}, nil
+}
+
+// UpdateDraftNoteHandler handles the updateDraftNote tool request.
+func UpdateDraftNoteHandler(ctx context.Context, request mcp.CallToolRequest, client *gitlab.Client, config Config) (*mcp.CallToolResult, error) {
+	draftNoteID, err := request.RequireInt("draftNoteID")
+	if err != nil {
+		return toolError(err), nil
+	}
+
+	body, err := request.RequireString("body")
+	if err != nil {
+		return toolError(err), nil
+	}
+
+	mr, err := ResolveMergeRequest(ctx, request, client, config)
+	if err != nil {
+		return toolError(err), nil
+	}
+
+	if _, err := client.UpdateDraftNote(ctx, mr.ProjectID, mr.IID, draftNoteID, body); err != nil {
+		return toolError(err), nil
+	}
+
+	return mcp.NewToolResultText(fmt.Sprintf("Updated draft note %d on !%d", draftNoteID, mr.IID)), nil
+}
+
+// DeleteDraftNoteHandler handles the deleteDraftNote tool request.
+func DeleteDraftNoteHandler(ctx context.Context, request mcp.CallToolRequest, client *gitlab.Client, config Config) (*mcp.CallToolResult, error) {
+	draftNoteID, err := request.RequireInt("draftNoteID")
+	if err != nil {
+		return toolError(err), nil
+	}
+
+	mr, err := ResolveMergeRequest(ctx, request, client, config)
+	if err != nil {
+		return toolError(err), nil
+	}
+
+	if err := client.DeleteDraftNote(ctx, mr.ProjectID, mr.IID, draftNoteID); err != nil {
+		return toolError(err), nil
+	}
+
+	return mcp.NewToolResultText(fmt.Sprintf("Deleted draft note %d on !%d", draftNoteID, mr.IID)), nil
+}
+
+// PublishDraftNotesHandler handles the publishDraftNotes tool request.
+func PublishDraftNotesHandler(ctx context.Context, request mcp.CallToolRequest, client *gitlab.Client, config Config) (*mcp.CallToolResult, error) {
+	draftNoteID := request.GetInt("draftNoteID", 0)
+
+	mr, err := ResolveMergeRequest(ctx, request, client, config)
+	if err != nil {
+		return toolError(err), nil
+	}
+
+	if draftNoteID != 0 {
+		if err := client.PublishDraftNote(ctx, mr.ProjectID, mr.IID, draftNoteID); err != nil {
+			return toolError(err), nil
+		}
+		return mcp.NewToolResultText(fmt.Sprintf("Published draft note %d on !%d", draftNoteID, mr.IID)), nil
+	}
+
+	if err := client.PublishAllDraftNotes(ctx, mr.ProjectID, mr.IID); err != nil {
+		return toolError(err), nil
+	}
+
+	return mcp.NewToolResultText(fmt.Sprintf("Published all draft notes on !%d", mr.IID)), nil
+}
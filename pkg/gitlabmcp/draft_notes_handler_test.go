@@ -0,0 +1,187 @@
+package gitlabmcp
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/ondratuma/gitlab-review-mcp/pkg/gitlab"
+)
+
+// TestDraftNoteHandlers tests the draft note handlers
+func TestDraftNoteHandlers(t *testing.T) {
+	config := Config{ProjectID: "123"}
+
+	versionDiff := `{"id":2,"head_commit_sha":"head","base_commit_sha":"base","start_commit_sha":"start",` +
+		`"diffs":[{"old_path":"main.go","new_path":"main.go","diff":"@@ -1,2 +1,3 @@\n package main\n+\n+// Doc\n"}]}`
+
+	tests := []struct {
+		name      string
+		handler   func(context.Context, mcp.CallToolRequest, *gitlab.Client, Config) (*mcp.CallToolResult, error)
+		arguments map[string]any
+		bodies    map[string]string
+		// expectedRequests are the requests sent, as "METHOD path"
+		expectedRequests []string
+		// expectedPayload is contained in the body of the last request
+		expectedPayload string
+		expectedText    string
+		expectError     bool
+	}{
+		{
+			name:      "create general draft note",
+			handler:   CreateDraftNoteHandler,
+			arguments: map[string]any{"mergeRequest": "!7", "body": "Looks good"},
+			bodies: map[string]string{
+				"/api/v4/projects/123/merge_requests/7/draft_notes": `{"id":5}`,
+			},
+			expectedRequests: []string{"POST /api/v4/projects/123/merge_requests/7/draft_notes"},
+			expectedPayload:  `"note":"Looks good"`,
+			expectedText:     "Created draft note 5 on !7",
+		},
+		{
+			name:      "create inline draft note on the latest version",
+			handler:   CreateDraftNoteHandler,
+			arguments: map[string]any{"mergeRequest": "!7", "body": "Document this", "path": "main.go", "line": float64(3)},
+			bodies: map[string]string{
+				"/api/v4/projects/123/merge_requests/7/versions":    `[{"id":2},{"id":1}]`,
+				"/api/v4/projects/123/merge_requests/7/versions/2":  versionDiff,
+				"/api/v4/projects/123/merge_requests/7/draft_notes": `{"id":6}`,
+			},
+			expectedRequests: []string{
+				"GET /api/v4/projects/123/merge_requests/7/versions",
+				"GET /api/v4/projects/123/merge_requests/7/versions/2",
+				"POST /api/v4/projects/123/merge_requests/7/draft_notes",
+			},
+			expectedPayload: `"head_sha":"head"`,
+			expectedText:    "Created draft note 6 on !7",
+		},
+		{
+			name:      "reply draft note",
+			handler:   CreateDraftNoteHandler,
+			arguments: map[string]any{"mergeRequest": "!7", "body": "Done", "discussionID": "abc", "resolveDiscussion": true},
+			bodies: map[string]string{
+				"/api/v4/projects/123/merge_requests/7/draft_notes": `{"id":8}`,
+			},
+			expectedRequests: []string{"POST /api/v4/projects/123/merge_requests/7/draft_notes"},
+			expectedPayload:  `"in_reply_to_discussion_id":"abc","resolve_discussion":true`,
+			expectedText:     "Created draft note 8 on !7",
+		},
+		{
+			name:         "inline reply is rejected",
+			handler:      CreateDraftNoteHandler,
+			arguments:    map[string]any{"mergeRequest": "!7", "body": "Done", "discussionID": "abc", "path": "main.go", "line": float64(3)},
+			expectedText: "either a reply to a discussion or an inline comment",
+			expectError:  true,
+		},
+		{
+			name:      "line outside the diff",
+			handler:   CreateDraftNoteHandler,
+			arguments: map[string]any{"mergeRequest": "!7", "body": "Hmm", "path": "main.go", "line": float64(30)},
+			bodies: map[string]string{
+				"/api/v4/projects/123/merge_requests/7/versions":   `[{"id":2}]`,
+				"/api/v4/projects/123/merge_requests/7/versions/2": versionDiff,
+			},
+			expectedRequests: []string{
+				"GET /api/v4/projects/123/merge_requests/7/versions",
+				"GET /api/v4/projects/123/merge_requests/7/versions/2",
+			},
+			expectedText: "line 30 (new) of main.go is not part of the diff",
+			expectError:  true,
+		},
+		{
+			name:      "list draft notes",
+			handler:   ListDraftNotesHandler,
+			arguments: map[string]any{"mergeRequest": "!7"},
+			bodies: map[string]string{
+				"/api/v4/projects/123/merge_requests/7/draft_notes": `[{"id":5,"note":"Looks good"},{"id":6,"note":"Reply","discussion_id":"abc"}]`,
+			},
+			expectedRequests: []string{"GET /api/v4/projects/123/merge_requests/7/draft_notes"},
+			expectedText:     "Found 2 draft note(s) on !7",
+		},
+		{
+			name:      "update draft note",
+			handler:   UpdateDraftNoteHandler,
+			arguments: map[string]any{"mergeRequest": "!7", "draftNoteID": float64(5), "body": "Looks great"},
+			bodies: map[string]string{
+				"/api/v4/projects/123/merge_requests/7/draft_notes/5": `{"id":5}`,
+			},
+			expectedRequests: []string{"PUT /api/v4/projects/123/merge_requests/7/draft_notes/5"},
+			expectedPayload:  `"note":"Looks great"`,
+			expectedText:     "Updated draft note 5 on !7",
+		},
+		{
+			name:             "delete draft note",
+			handler:          DeleteDraftNoteHandler,
+			arguments:        map[string]any{"mergeRequest": "!7", "draftNoteID": float64(5)},
+			expectedRequests: []string{"DELETE /api/v4/projects/123/merge_requests/7/draft_notes/5"},
+			expectedText:     "Deleted draft note 5 on !7",
+		},
+		{
+			name:         "delete requires an ID",
+			handler:      DeleteDraftNoteHandler,
+			arguments:    map[string]any{"mergeRequest": "!7"},
+			expectedText: "draftNoteID",
+			expectError:  true,
+		},
+		{
+			name:             "publish single draft note",
+			handler:          PublishDraftNotesHandler,
+			arguments:        map[string]any{"mergeRequest": "!7", "draftNoteID": float64(5)},
+			expectedRequests: []string{"PUT /api/v4/projects/123/merge_requests/7/draft_notes/5/publish"},
+			expectedText:     "Published draft note 5 on !7",
+		},
+		{
+			name:             "publish all draft notes",
+			handler:          PublishDraftNotesHandler,
+			arguments:        map[string]any{"mergeRequest": "!7"},
+			expectedRequests: []string{"POST /api/v4/projects/123/merge_requests/7/draft_notes/bulk_publish"},
+			expectedText:     "Published all draft notes on !7",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			httpClient := &mockHTTPClient{bodies: tc.bodies}
+			client := gitlab.NewClient("test-token")
+			client.HTTPClient = httpClient
+
+			request := mcp.CallToolRequest{}
+			request.Params.Arguments = tc.arguments
+
+			result, err := tc.handler(context.Background(), request, client, config)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if result.IsError != tc.expectError {
+				t.Errorf("expected error result %t, got %+v", tc.expectError, result)
+			}
+			var text strings.Builder
+			for _, content := range result.Content {
+				text.WriteString(content.(mcp.TextContent).Text)
+			}
+			if !strings.Contains(text.String(), tc.expectedText) {
+				t.Errorf("expected result containing %q, got %q", tc.expectedText, text.String())
+			}
+
+			if len(httpClient.requests) != len(tc.expectedRequests) {
+				t.Fatalf("expected %d request(s), got %d", len(tc.expectedRequests), len(httpClient.requests))
+			}
+			for i, req := range httpClient.requests {
+				if got := req.Method + " " + req.URL.Path; got != tc.expectedRequests[i] {
+					t.Errorf("expected request %q, got %q", tc.expectedRequests[i], got)
+				}
+			}
+
+			if tc.expectedPayload != "" {
+				last := httpClient.requests[len(httpClient.requests)-1]
+				payload, _ := io.ReadAll(last.Body)
+				if !strings.Contains(string(payload), tc.expectedPayload) {
+					t.Errorf("expected payload containing %s, got %s", tc.expectedPayload, payload)
+				}
+			}
+		})
+	}
+}
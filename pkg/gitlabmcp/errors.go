@@ -0,0 +1,49 @@
+// Package gitlabmcp provides the GitLab MCP tool functionality.
+package gitlabmcp
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/ondratuma/gitlab-review-mcp/pkg/gitlab"
+)
+
+// toolError returns the error result of a failed tool call. GitLab API errors
+// are prefixed with a hint on how to fix them.
+func toolError(err error) *mcp.CallToolResult {
+	if hint := errorHint(err); hint != "" {
+		return mcp.NewToolResultError(fmt.Sprintf("%s\n%s", hint, err.Error()))
+	}
+	return mcp.NewToolResultError(err.Error())
+}
+
+// errorHint explains a GitLab API error in terms of what to check, or returns
+// an empty string for other errors.
+func errorHint(err error) string {
+	var apiErr *gitlab.APIError
+	if !errors.As(err, &apiErr) {
+		return ""
+	}
+
+	switch {
+	case errors.Is(err, gitlab.ErrUnauthorized):
+		return "GitLab rejected the token, check that GITLAB_TOKEN is valid and not expired."
+	case errors.Is(err, gitlab.ErrForbidden) && apiErr.Scope != "":
+		return fmt.Sprintf("The token lacks the required scope, it needs one of: %s.", strings.Join(strings.Fields(apiErr.Scope), ", "))
+	case errors.Is(err, gitlab.ErrForbidden):
+		return "The token's user is not allowed to do this, check their role in the project."
+	case errors.Is(err, gitlab.ErrNotFound) && strings.Contains(apiErr.Message, "Project"):
+		// GitLab hides projects the token cannot access behind 404s
+		return "Project not found, check GITLAB_PROJECT_ID or the project argument, and that the token can access the project."
+	case errors.Is(err, gitlab.ErrNotFound):
+		return "Not found, check the merge request, discussion or job ID, and that the token can access the project."
+	case errors.Is(err, gitlab.ErrRateLimited):
+		return "GitLab is rate limiting the token, try again later."
+	case errors.Is(err, gitlab.ErrServer):
+		return "GitLab is unavailable or failed, try again later."
+	}
+
+	return ""
+}
@@ -0,0 +1,66 @@
+package gitlabmcp
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/ondratuma/gitlab-review-mcp/pkg/gitlab"
+)
+
+// TestToolError tests that GitLab API errors are explained in tool results
+func TestToolError(t *testing.T) {
+	tests := []struct {
+		name         string
+		err          error
+		expectedHint string
+	}{
+		{
+			name:         "unauthorized",
+			err:          &gitlab.APIError{StatusCode: http.StatusUnauthorized},
+			expectedHint: "check that GITLAB_TOKEN is valid",
+		},
+		{
+			name:         "insufficient scope",
+			err:          &gitlab.APIError{StatusCode: http.StatusForbidden, Scope: "api read_api"},
+			expectedHint: "needs one of: api, read_api",
+		},
+		{
+			name:         "project not found",
+			err:          fmt.Errorf("project foo/bar: %w", &gitlab.APIError{StatusCode: http.StatusNotFound, Message: "404 Project Not Found"}),
+			expectedHint: "check GITLAB_PROJECT_ID",
+		},
+		{
+			name:         "merge request not found",
+			err:          &gitlab.APIError{StatusCode: http.StatusNotFound, Message: "404 Not found"},
+			expectedHint: "check the merge request",
+		},
+		{
+			name: "other errors",
+			err:  errors.New("no merge request found"),
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			result := toolError(tc.err)
+			if !result.IsError {
+				t.Fatal("expected an error result")
+			}
+
+			text := result.Content[0].(mcp.TextContent).Text
+			if !strings.HasSuffix(text, tc.err.Error()) {
+				t.Errorf("expected the result to end with the error, got %q", text)
+			}
+			if tc.expectedHint == "" && text != tc.err.Error() {
+				t.Errorf("expected no hint, got %q", text)
+			}
+			if !strings.Contains(text, tc.expectedHint) {
+				t.Errorf("expected hint %q, got %q", tc.expectedHint, text)
+			}
+		})
+	}
+}
@@ -0,0 +1,54 @@
+// Package gitlabmcp provides the GitLab MCP tool functionality.
+package gitlabmcp
+
+import (
+	"regexp"
+	"strings"
+)
+
+// MatchPathGlob reports whether filePath matches the glob pattern. Besides
+// the usual * and ? wildcards, ** matches any number of directories. Patterns
+// without a slash are matched against the file name only.
+func MatchPathGlob(pattern, filePath string) (bool, error) {
+	if !strings.Contains(pattern, "/") {
+		filePath = filePath[strings.LastIndex(filePath, "/")+1:]
+	}
+
+	re, err := globToRegexp(pattern)
+	if err != nil {
+		return false, err
+	}
+
+	return re.MatchString(filePath), nil
+}
+
+// globToRegexp translates a glob pattern into an anchored regular expression.
+func globToRegexp(pattern string) (*regexp.Regexp, error) {
+	var sb strings.Builder
+	sb.WriteString("^")
+
+	// Runes, not bytes, so non-ASCII characters are quoted whole
+	runes := []rune(pattern)
+	for i := 0; i < len(runes); i++ {
+		switch r := runes[i]; r {
+		case '*':
+			rest := string(runes[i:])
+			if strings.HasPrefix(rest, "**/") {
+				sb.WriteString("(?:.*/)?")
+				i += 2
+			} else if strings.HasPrefix(rest, "**") {
+				sb.WriteString(".*")
+				i++
+			} else {
+				sb.WriteString("[^/]*")
+			}
+		case '?':
+			sb.WriteString("[^/]")
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+
+	sb.WriteString("$")
+	return regexp.Compile(sb.String())
+}
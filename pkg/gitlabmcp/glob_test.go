@@ -0,0 +1,38 @@
+package gitlabmcp
+
+import (
+	"testing"
+)
+
+// TestMatchPathGlob tests the MatchPathGlob function
+func TestMatchPathGlob(t *testing.T) {
+	tests := []struct {
+		pattern  string
+		path     string
+		expected bool
+	}{
+		{pattern: "*.go", path: "pkg/gitlab/client.go", expected: true},
+		{pattern: "*.go", path: "README.md", expected: false},
+		{pattern: "pkg/*.go", path: "pkg/gitlab/client.go", expected: false},
+		{pattern: "pkg/**/*.go", path: "pkg/gitlab/client.go", expected: true},
+		{pattern: "pkg/**/*.go", path: "pkg/main.go", expected: true},
+		{pattern: "pkg/**", path: "pkg/gitlab/types.go", expected: true},
+		{pattern: "cmd/**", path: "pkg/gitlab/types.go", expected: false},
+		{pattern: "client?.go", path: "pkg/gitlab/client1.go", expected: true},
+		{pattern: "main.go", path: "main.go", expected: true},
+		{pattern: "docs/ü.md", path: "docs/ü.md", expected: true},
+		{pattern: "docs/?.md", path: "docs/ü.md", expected: true},
+		{pattern: "**/*ß*.go", path: "pkg/straße.go", expected: true},
+	}
+
+	for _, tc := range tests {
+		got, err := MatchPathGlob(tc.pattern, tc.path)
+		if err != nil {
+			t.Errorf("MatchPathGlob(%q, %q): unexpected error: %v", tc.pattern, tc.path, err)
+			continue
+		}
+		if got != tc.expected {
+			t.Errorf("MatchPathGlob(%q, %q): expected %t, got %t", tc.pattern, tc.path, tc.expected, got)
+		}
+	}
+}
@@ -7,27 +7,52 @@ import (
 	"strings"
 
 	"github.com/mark3labs/mcp-go/mcp"
-	"github.com/ondratuma/gitlab-review-mcp/pkg/git"
 	"github.com/ondratuma/gitlab-review-mcp/pkg/gitlab"
 )
 
-// GetMergeRequestInfoHandler handles the getMergeRequestInfo tool request.
-func GetMergeRequestInfoHandler(ctx context.Context, request mcp.CallToolRequest, config Config) (*mcp.CallToolResult, error) {
-	branch, err := git.GetCurrentBranch()
-	if err != nil {
-		return mcp.NewToolResultError(err.Error()), nil
+// FormatMergeRequest formats the general information of a merge request.
+func FormatMergeRequest(mr gitlab.MergeRequest) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("%s: %s\n", mr.Reference(), mr.Title))
+	if mr.Description != "" {
+		sb.WriteString(fmt.Sprintf("Description: %s\n", mr.Description))
 	}
+	if mr.Author != nil && mr.Author.UserName != nil {
+		sb.WriteString(fmt.Sprintf("Author: %s\n", *mr.Author.UserName))
+	}
+	sb.WriteString(fmt.Sprintf("Source Branch: %s\n", mr.SourceBranch))
+	sb.WriteString(fmt.Sprintf("Target Branch: %s\n", mr.TargetBranch))
+	sb.WriteString(fmt.Sprintf("State: %s\n", mr.State))
+	sb.WriteString(fmt.Sprintf("URL: %s", mr.WebURL))
+
+	return sb.String()
+}
+
+// GetMergeRequestInfoHandler handles the getMergeRequestInfo tool request.
+func GetMergeRequestInfoHandler(ctx context.Context, request mcp.CallToolRequest, client *gitlab.Client, config Config) (*mcp.CallToolResult, error) {
+	// A specific merge request was asked for
+	if _, ok := request.GetArguments()["mergeRequest"]; ok {
+		target, err := ResolveMergeRequest(ctx, request, client, config)
+		if err != nil {
+			return toolError(err), nil
+		}
+
+		mr, err := client.GetMergeRequest(ctx, target.ProjectID, target.IID)
+		if err != nil {
+			return toolError(err), nil
+		}
 
-	client := gitlab.NewClient(config.GitLabToken)
-	sourceBranch := branch
+		return mcp.NewToolResultText(FormatMergeRequest(*mr)), nil
+	}
 
-	mrs, err := client.GetMergeRequestsBySourceBranch(config.ProjectID, sourceBranch)
+	scope := config.searchScope(requestProject(request, config))
+	mrs, source, err := currentMergeRequests(ctx, client, scope, repoPath(request, config))
 	if err != nil {
-		return mcp.NewToolResultError(err.Error()), nil
+		return toolError(err), nil
 	}
 
 	if len(mrs) == 0 {
-		return mcp.NewToolResultText(fmt.Sprintf("No merge requests found for the source branch (%s)", branch)), nil
+		return mcp.NewToolResultText(fmt.Sprintf("No merge requests found for the %s", source)), nil
 	}
 
 	// Create a content slice to hold each MR as a separate content item
@@ -36,28 +61,15 @@ func GetMergeRequestInfoHandler(ctx context.Context, request mcp.CallToolRequest
 	// Add a header content with the count of MRs found
 	headerContent := mcp.TextContent{
 		Type: "text",
-		Text: fmt.Sprintf("Found %d merge request(s) for branch %s", len(mrs), branch),
+		Text: fmt.Sprintf("Found %d merge request(s) for %s", len(mrs), source),
 	}
 	contents = append(contents, headerContent)
 
 	// Add each MR as a separate content item
 	for _, mr := range mrs {
-		var sb strings.Builder
-		sb.WriteString(fmt.Sprintf("!%d: %s\n", mr.IID, mr.Title))
-		if mr.Description != "" {
-			sb.WriteString(fmt.Sprintf("Description: %s\n", mr.Description))
-		}
-		if mr.Author != nil && mr.Author.UserName != nil {
-			sb.WriteString(fmt.Sprintf("Author: %s\n", *mr.Author.UserName))
-		}
-		sb.WriteString(fmt.Sprintf("Source Branch: %s\n", mr.SourceBranch))
-		sb.WriteString(fmt.Sprintf("Target Branch: %s\n", mr.TargetBranch))
-		sb.WriteString(fmt.Sprintf("State: %s\n", mr.State))
-		sb.WriteString(fmt.Sprintf("URL: %s", mr.WebURL))
-
 		mrContent := mcp.TextContent{
 			Type: "text",
-			Text: sb.String(),
+			Text: FormatMergeRequest(mr.MergeRequest),
 		}
 		contents = append(contents, mrContent)
 	}
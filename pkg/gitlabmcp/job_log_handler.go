@@ -0,0 +1,196 @@
+// Package gitlabmcp provides the GitLab MCP tool functionality.
+package gitlabmcp
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/ondratuma/gitlab-review-mcp/pkg/gitlab"
+)
+
+// DefaultJobLogBytes is the default size budget of a trimmed job log.
+const DefaultJobLogBytes = 16000
+
+// maxJobLogLineLength is the length long lines, e.g. of minified output, are cut to.
+const maxJobLogLineLength = 1000
+
+// maxJobTraceBytes is the size of the end of a job log that is downloaded.
+// Failure patterns are only searched in this part.
+const maxJobTraceBytes = 4 << 20
+
+// failurePatterns match log lines that commonly explain why a job failed.
+var failurePatterns = []*regexp.Regexp{
+	// Go test failures
+	regexp.MustCompile(`^\s*--- FAIL: `),
+	regexp.MustCompile(`^FAIL(\s|$)`),
+	// Panics and runtime errors
+	regexp.MustCompile(`^panic: `),
+	regexp.MustCompile(`^fatal error: `),
+	regexp.MustCompile(`Traceback \(most recent call last\)`),
+	// Compile errors and test assertions, e.g. "main.go:12:3: undefined: foo"
+	regexp.MustCompile(`^\s*[\w./-]+\.\w+:\d+(:\d+)?: `),
+	// Error lines of most tools, including the runner's "ERROR: Job failed"
+	regexp.MustCompile(`(?i)^\s*(error|fatal)(:|\[| )`),
+	regexp.MustCompile(`^npm ERR! `),
+}
+
+// isFailureLine reports whether a log line matches a failure pattern.
+func isFailureLine(text string) bool {
+	for _, pattern := range failurePatterns {
+		if pattern.MatchString(text) {
+			return true
+		}
+	}
+	return false
+}
+
+// truncateLine cuts overly long log lines, keeping multi-byte characters whole.
+func truncateLine(text string) string {
+	if len(text) <= maxJobLogLineLength {
+		return text
+	}
+
+	cut := maxJobLogLineLength
+	for cut > 0 && !utf8.RuneStart(text[cut]) {
+		cut--
+	}
+	return text[:cut] + "…"
+}
+
+// foldCollapsedSections replaces the lines of collapsed sections with a single
+// line per section. Nested sections are folded into the collapsed one.
+func foldCollapsedSections(lines []gitlab.TraceLine) []gitlab.TraceLine {
+	var folded []gitlab.TraceLine
+	for i := 0; i < len(lines); i++ {
+		if !lines[i].Collapsed {
+			folded = append(folded, lines[i])
+			continue
+		}
+
+		start := i
+		for i+1 < len(lines) && lines[i+1].Collapsed && lines[i+1].CollapsedSection == lines[start].CollapsedSection {
+			i++
+		}
+		folded = append(folded, gitlab.TraceLine{
+			Number: lines[start].Number,
+			Text:   fmt.Sprintf("[%d line(s) of collapsed section %s]", i-start+1, lines[start].CollapsedSection),
+		})
+	}
+
+	return folded
+}
+
+// TrimJobLog reduces a job log to about maxBytes. Collapsed sections are
+// folded and the tail of the log is kept, preceded by lines anywhere in the
+// log that match common failure patterns.
+func TrimJobLog(lines []gitlab.TraceLine, maxBytes int) string {
+	visible := foldCollapsedSections(lines)
+
+	var full strings.Builder
+	for _, line := range visible {
+		full.WriteString(truncateLine(line.Text) + "\n")
+	}
+	if full.Len() <= maxBytes {
+		return full.String()
+	}
+
+	var matches []gitlab.TraceLine
+	for _, line := range lines {
+		if isFailureLine(line.Text) {
+			matches = append(matches, line)
+		}
+	}
+
+	// Matches take at most half of the budget, the tail the rest
+	formatMatches := func(matches []gitlab.TraceLine) string {
+		if len(matches) == 0 {
+			return ""
+		}
+		var sb strings.Builder
+		sb.WriteString("Lines matching failure patterns:\n")
+		for i, match := range matches {
+			text := fmt.Sprintf("%d: %s\n", match.Number, truncateLine(match.Text))
+			if sb.Len()+len(text) > maxBytes/2 {
+				sb.WriteString(fmt.Sprintf("... %d more matching line(s)\n", len(matches)-i))
+				break
+			}
+			sb.WriteString(text)
+		}
+		sb.WriteString("\n")
+		return sb.String()
+	}
+
+	remaining := maxBytes - len(formatMatches(matches))
+	tailStart := len(visible)
+	size := 0
+	for tailStart > 0 {
+		lineSize := len(truncateLine(visible[tailStart-1].Text)) + 1
+		if size+lineSize > remaining {
+			break
+		}
+		size += lineSize
+		tailStart--
+	}
+
+	// Matches shown in the tail anyway are not repeated
+	var earlier []gitlab.TraceLine
+	for _, match := range matches {
+		if match.Collapsed || tailStart == len(visible) || match.Number < visible[tailStart].Number {
+			earlier = append(earlier, match)
+		}
+	}
+
+	var sb strings.Builder
+	sb.WriteString(formatMatches(earlier))
+	sb.WriteString(fmt.Sprintf("Last %d line(s), %d earlier line(s) omitted:\n", len(visible)-tailStart, tailStart))
+	for _, line := range visible[tailStart:] {
+		sb.WriteString(truncateLine(line.Text) + "\n")
+	}
+
+	return sb.String()
+}
+
+// GetJobLogHandler handles the getJobLog tool request.
+func GetJobLogHandler(ctx context.Context, request mcp.CallToolRequest, client *gitlab.Client, config Config) (*mcp.CallToolResult, error) {
+	jobID, err := request.RequireInt("jobID")
+	if err != nil {
+		return toolError(err), nil
+	}
+
+	maxBytes := request.GetInt("maxBytes", DefaultJobLogBytes)
+	if maxBytes <= 0 {
+		return mcp.NewToolResultError("maxBytes must be positive"), nil
+	}
+
+	projectID := request.GetString("project", config.ProjectID)
+	if projectID == "" {
+		return mcp.NewToolResultError("no default project is configured, pass project"), nil
+	}
+
+	job, err := client.GetJob(ctx, projectID, jobID)
+	if err != nil {
+		return toolError(err), nil
+	}
+
+	trace, truncated, err := client.GetJobTrace(ctx, projectID, jobID, maxJobTraceBytes)
+	if err != nil {
+		return toolError(err), nil
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Log of job %d %s (stage %s): %s", job.ID, job.Name, job.Stage, job.Status))
+	if job.FailureReason != "" {
+		sb.WriteString(fmt.Sprintf(", reason: %s", job.FailureReason))
+	}
+	sb.WriteString("\n\n")
+	if truncated {
+		sb.WriteString(fmt.Sprintf("The log is longer than %d MiB, only its end was read and line numbers count from there.\n\n", maxJobTraceBytes>>20))
+	}
+	sb.WriteString(TrimJobLog(gitlab.ParseJobTrace(trace), maxBytes))
+
+	return mcp.NewToolResultText(sb.String()), nil
+}
@@ -0,0 +1,120 @@
+package gitlabmcp
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/ondratuma/gitlab-review-mcp/pkg/gitlab"
+)
+
+func TestTrimJobLog(t *testing.T) {
+	lines := []gitlab.TraceLine{
+		{Number: 1, Text: "Installing dependencies", Section: "deps"},
+		{Number: 2, Text: "fetching a", Section: "deps", Collapsed: true, CollapsedSection: "deps"},
+		{Number: 3, Text: "fetching b", Section: "deps", Collapsed: true, CollapsedSection: "deps"},
+	}
+	for i := 4; i <= 100; i++ {
+		lines = append(lines, gitlab.TraceLine{Number: i, Text: fmt.Sprintf("=== RUN TestCase%d", i)})
+	}
+	lines[50].Text = "--- FAIL: TestCase51 (0.00s)"
+	lines[51].Text = "    main_test.go:12: expected 1, got 2"
+	lines = append(lines,
+		gitlab.TraceLine{Number: 101, Text: "FAIL"},
+		gitlab.TraceLine{Number: 102, Text: "ERROR: Job failed: exit code 1"},
+	)
+
+	t.Run("fits the budget", func(t *testing.T) {
+		actual := TrimJobLog(lines, 100000)
+		if !strings.HasPrefix(actual, "Installing dependencies\n[2 line(s) of collapsed section deps]\n=== RUN TestCase4\n") {
+			t.Errorf("expected the whole log with folded sections, got:\n%s", actual)
+		}
+		if strings.Contains(actual, "fetching") || strings.Contains(actual, "omitted") {
+			t.Errorf("expected no collapsed lines and no trimming, got:\n%s", actual)
+		}
+	})
+
+	t.Run("trimmed", func(t *testing.T) {
+		actual := TrimJobLog(lines, 400)
+		expected := "Lines matching failure patterns:\n" +
+			"51: --- FAIL: TestCase51 (0.00s)\n" +
+			"52:     main_test.go:12: expected 1, got 2\n" +
+			"\n" +
+			"Last 12 line(s), 89 earlier line(s) omitted:\n"
+		if !strings.HasPrefix(actual, expected) {
+			t.Errorf("expected output starting with:\n%s\ngot:\n%s", expected, actual)
+		}
+		if !strings.HasSuffix(actual, "=== RUN TestCase100\nFAIL\nERROR: Job failed: exit code 1\n") {
+			t.Errorf("expected the tail of the log, got:\n%s", actual)
+		}
+		// Matches within the tail are not repeated
+		if strings.Contains(actual, "101: FAIL") {
+			t.Errorf("expected matches in the tail to be skipped, got:\n%s", actual)
+		}
+		if len(actual) > 400+len("Last 12 line(s), 89 earlier line(s) omitted:\n") {
+			t.Errorf("expected about 400 bytes, got %d", len(actual))
+		}
+	})
+}
+
+func TestIsFailureLine(t *testing.T) {
+	tests := []struct {
+		text     string
+		expected bool
+	}{
+		{"--- FAIL: TestParse (0.01s)", true},
+		{"    --- FAIL: TestParse/empty (0.00s)", true},
+		{"FAIL\tgithub.com/example/app\t0.012s", true},
+		{"panic: runtime error: index out of range [3] with length 3", true},
+		{"pkg/app/main.go:12:3: undefined: foo", true},
+		{"ERROR: Job failed: exit code 1", true},
+		{"error: failed to push some refs", true},
+		{"ok  \tgithub.com/example/app\t0.012s", false},
+		{"=== RUN   TestParse", false},
+		{"Fetching changes with git depth set to 20...", false},
+	}
+
+	for _, tc := range tests {
+		if actual := isFailureLine(tc.text); actual != tc.expected {
+			t.Errorf("isFailureLine(%q) = %v, expected %v", tc.text, actual, tc.expected)
+		}
+	}
+}
+
+func TestFoldCollapsedSections(t *testing.T) {
+	// A section nested in a collapsed one is hidden with it
+	lines := []gitlab.TraceLine{
+		{Number: 1, Text: "Building", Section: "build"},
+		{Number: 2, Text: "compiling", Section: "build", Collapsed: true, CollapsedSection: "build"},
+		{Number: 3, Text: "Building assets", Section: "assets", Collapsed: true, CollapsedSection: "build"},
+		{Number: 4, Text: "minifying", Section: "assets", Collapsed: true, CollapsedSection: "build"},
+		{Number: 5, Text: "Done"},
+	}
+
+	expected := []gitlab.TraceLine{
+		{Number: 1, Text: "Building", Section: "build"},
+		{Number: 2, Text: "[3 line(s) of collapsed section build]"},
+		{Number: 5, Text: "Done"},
+	}
+
+	actual := foldCollapsedSections(lines)
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected %+v, got %+v", expected, actual)
+	}
+}
+
+func TestTruncateLine(t *testing.T) {
+	short := "ok"
+	if actual := truncateLine(short); actual != short {
+		t.Errorf("expected %q to be kept, got %q", short, actual)
+	}
+
+	// The cut falls into the middle of "é"
+	long := strings.Repeat("a", maxJobLogLineLength-1) + strings.Repeat("é", 10)
+	actual := truncateLine(long)
+	if !utf8.ValidString(actual) || actual != strings.Repeat("a", maxJobLogLineLength-1)+"…" {
+		t.Errorf("expected the line cut before the split character, got %q", actual[maxJobLogLineLength-5:])
+	}
+}
@@ -3,17 +3,56 @@ package gitlabmcp
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
 
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
+	"github.com/ondratuma/gitlab-review-mcp/pkg/gitlab"
 )
 
 // Run starts the GitLab MCP tool with the provided configuration.
-func Run(gitlabToken, projectID string) error {
-	// Create configuration from provided values
-	config := Config{
-		GitLabToken: gitlabToken,
-		ProjectID:   projectID,
+func Run(config Config) error {
+	// Running tool calls are cancelled on SIGINT or SIGTERM
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// Fall back to the instance hosting the git remote
+	if config.GitLabURL == "" {
+		gitLabURL, err := detectGitLabInstance(ctx, config)
+		if err != nil {
+			return err
+		}
+		// Stdout carries the protocol, the default logger writes to stderr
+		slog.Info("GITLAB_URL is not set, using the instance of the git remote", "url", gitLabURL)
+		config.GitLabURL = gitLabURL
+	}
+
+	// Derive the project from the git remote unless set explicitly. A
+	// default project is optional when serving a group.
+	if config.ProjectID == "" {
+		projectID, err := DetectProjectID(config.RepoPath, config.GitRemote, config.GitLabURL)
+		if err != nil && config.Group == "" {
+			return fmt.Errorf("GITLAB_PROJECT_ID is not set and could not be detected: %w", err)
+		}
+		if err != nil {
+			slog.Warn("GITLAB_PROJECT_ID is not set and could not be detected, merge requests given by IID alone need a project argument or a group/project!IID reference",
+				"group", config.Group, "error", err)
+		}
+		config.ProjectID = projectID
+	}
+
+	// The client is shared by all tool calls, so connections are reused and
+	// invalid TLS settings fail early
+	client, err := newGitLabClient(config)
+	if err != nil {
+		return err
 	}
 
 	// Create a new MCP server
@@ -21,47 +60,476 @@ func Run(gitlabToken, projectID string) error {
 		"GitLab Merge Request MCP",
 		"0.0.1",
 		server.WithToolCapabilities(false),
+		server.WithToolHandlerMiddleware(withToolTimeout(config.ToolTimeout)),
+		server.WithToolHandlerMiddleware(withListCapNotes(config.MaxListItems)),
 	)
 
 	// Create and register tools with logging middleware
-	registerTools(s, config)
+	registerTools(s, client, config)
 
 	// Start the stdio server
-	return server.ServeStdio(s)
+	return serveStdio(ctx, s, os.Stdin, os.Stdout)
+}
+
+// withToolTimeout limits every tool call to timeout, zero for no limit. Calls
+// failing because of it report the timeout instead of the error it caused.
+func withToolTimeout(timeout time.Duration) server.ToolHandlerMiddleware {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			if timeout <= 0 {
+				return next(ctx, request)
+			}
+
+			ctx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+
+			result, err := next(ctx, request)
+			failed := err != nil || result == nil || result.IsError
+			if failed && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				return mcp.NewToolResultError(fmt.Sprintf("%s timed out after %s", request.Params.Name, timeout)), nil
+			}
+
+			return result, err
+		}
+	}
+}
+
+// withListCapNotes appends a note to the results of tool calls that read
+// lists capped at maxItems, so agents know that items are missing.
+func withListCapNotes(maxItems int) server.ToolHandlerMiddleware {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			if maxItems <= 0 {
+				return next(ctx, request)
+			}
+
+			ctx, report := gitlab.WithListCapReport(ctx)
+			result, err := next(ctx, request)
+			if err != nil || result == nil {
+				return result, err
+			}
+
+			if capped := report.Capped(); len(capped) > 0 {
+				result.Content = append(result.Content, mcp.TextContent{
+					Type: "text",
+					Text: fmt.Sprintf("Note: lists capped at %d items by GITLAB_MAX_LIST_ITEMS, items are missing from: %s",
+						maxItems, strings.Join(capped, ", ")),
+				})
+			}
+			return result, nil
+		}
+	}
 }
 
 // registerTools registers all tools with the MCP server.
-func registerTools(s *server.MCPServer, config Config) {
+func registerTools(s *server.MCPServer, client *gitlab.Client, config Config) {
 	// Get current branch tool
 	getCurrentBranchTool := mcp.NewTool("get_current_branch",
 		mcp.WithDescription("Get the current Git branch"),
+		withRepoPath(),
 	)
-	s.AddTool(getCurrentBranchTool, GetCurrentBranchHandler)
+
+	// Wrap the branch handler to include the config
+	wrappedBranchHandler := func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return GetCurrentBranchHandler(ctx, request, config)
+	}
+	s.AddTool(getCurrentBranchTool, wrappedBranchHandler)
 
 	// Get merge request info tool
 	getMergeRequestInfoTool := mcp.NewTool("get_merge_request_info",
-		mcp.WithDescription("Get general information for merge requests from the currently checked out branch"),
+		mcp.WithDescription("Get general information for merge requests from the currently checked out branch, or for a specific merge request"),
+		mcp.WithString(
+			"mergeRequest",
+			mcp.Description("Merge request as IID, !IID, group/project!IID, web URL or source branch; all merge requests of the current branch are listed when omitted"),
+		),
+		withProject(),
+		withRepoPath(),
 	)
 
 	// Wrap the info handler to include the config
 	wrappedInfoHandler := func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-		return GetMergeRequestInfoHandler(ctx, request, config)
+		return GetMergeRequestInfoHandler(ctx, request, client, config)
 	}
 	s.AddTool(getMergeRequestInfoTool, wrappedInfoHandler)
 
 	// Get merge request comments tool
 	getMergeRequestCommentsTool := mcp.NewTool("get_merge_request_comments",
 		mcp.WithDescription("Get comments for merge requests from the currently checked out branch"),
+		withMergeRequest(),
+		withProject(),
+		withRepoPath(),
 		mcp.WithNumber(
-			"mergeRequestIID",
-			mcp.Required(),
-			mcp.Description("IID Of the Merge Request"),
+			"contextLines",
+			mcp.Description("Number of code lines shown around each commented line (default 3)"),
+		),
+		mcp.WithString(
+			"state",
+			mcp.Description("Only return threads in this resolution state (default all)"),
+			mcp.Enum(StateUnresolved, StateResolved, StateAll),
+		),
+		mcp.WithArray(
+			"authors",
+			mcp.Description("Only return threads started by these usernames"),
+			mcp.Items(map[string]any{"type": "string"}),
+		),
+		mcp.WithArray(
+			"excludeAuthors",
+			mcp.Description("Skip threads started by these usernames"),
+			mcp.Items(map[string]any{"type": "string"}),
+		),
+		mcp.WithString(
+			"since",
+			mcp.Description("Only return threads with comments since this date (YYYY-MM-DD or RFC 3339)"),
+		),
+		mcp.WithString(
+			"pathGlob",
+			mcp.Description("Only return threads on files matching this glob, e.g. \"pkg/**/*.go\""),
+		),
+		mcp.WithBoolean(
+			"includeGeneral",
+			mcp.Description("Include general discussion threads not attached to the diff (default true)"),
 		),
 	)
 
 	// Wrap the comments handler to include the config
 	wrappedCommentsHandler := func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-		return GetMergeRequestCommentsHandler(ctx, request, config)
+		return GetMergeRequestCommentsHandler(ctx, request, client, config)
 	}
 	s.AddTool(getMergeRequestCommentsTool, wrappedCommentsHandler)
+
+	// Reply to discussion tool
+	replyToDiscussionTool := mcp.NewTool("reply_to_discussion",
+		mcp.WithDescription("Reply to an existing review discussion thread on a merge request"),
+		withMergeRequest(),
+		withProject(),
+		withRepoPath(),
+		mcp.WithString(
+			"discussionID",
+			mcp.Required(),
+			mcp.Description("ID of the discussion thread to reply to"),
+		),
+		mcp.WithString(
+			"body",
+			mcp.Required(),
+			mcp.Description("Text of the reply, e.g. \"Fixed in abc123\""),
+		),
+	)
+
+	// Wrap the reply handler to include the config
+	wrappedReplyHandler := func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return ReplyToDiscussionHandler(ctx, request, client, config)
+	}
+	s.AddTool(replyToDiscussionTool, wrappedReplyHandler)
+
+	// Resolve discussion tool
+	resolveDiscussionTool := mcp.NewTool("resolve_discussion",
+		mcp.WithDescription("Resolve or unresolve a review discussion thread on a merge request"),
+		withMergeRequest(),
+		withProject(),
+		withRepoPath(),
+		mcp.WithString(
+			"discussionID",
+			mcp.Required(),
+			mcp.Description("ID of the discussion thread to resolve"),
+		),
+		mcp.WithBoolean(
+			"unresolve",
+			mcp.Description("Reopen the discussion thread instead of resolving it"),
+		),
+		mcp.WithBoolean(
+			"allowOthers",
+			mcp.Description("Allow resolving threads started by someone other than the token owner"),
+		),
+	)
+
+	// Wrap the resolve handler to include the config
+	wrappedResolveHandler := func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return ResolveDiscussionHandler(ctx, request, client, config)
+	}
+	s.AddTool(resolveDiscussionTool, wrappedResolveHandler)
+
+	// Get merge request changes tool
+	getMergeRequestChangesTool := mcp.NewTool("get_merge_request_changes",
+		mcp.WithDescription("Get the diff of a merge request as per-file hunks"),
+		withMergeRequest(),
+		withProject(),
+		withRepoPath(),
+		mcp.WithString(
+			"pathGlob",
+			mcp.Description("Only return files matching this glob, e.g. \"pkg/**/*.go\""),
+		),
+	)
+
+	// Wrap the changes handler to include the config
+	wrappedChangesHandler := func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return GetMergeRequestChangesHandler(ctx, request, client, config)
+	}
+	s.AddTool(getMergeRequestChangesTool, wrappedChangesHandler)
+
+	// Create diff comment tool
+	createDiffCommentTool := mcp.NewTool("create_diff_comment",
+		mcp.WithDescription("Start a new review discussion on a line of the merge request diff"),
+		withMergeRequest(),
+		withProject(),
+		withRepoPath(),
+		mcp.WithString(
+			"path",
+			mcp.Required(),
+			mcp.Description("Path of the file to comment on"),
+		),
+		mcp.WithNumber(
+			"line",
+			mcp.Required(),
+			mcp.Description("Line number to comment on, or the first line of a range"),
+		),
+		mcp.WithNumber(
+			"endLine",
+			mcp.Description("Last line of a multi-line comment range"),
+		),
+		mcp.WithString(
+			"side",
+			mcp.Description("Side of the diff the line numbers refer to: new (default) or old for removed lines"),
+			mcp.Enum(SideNew, SideOld),
+		),
+		mcp.WithString(
+			"body",
+			mcp.Required(),
+			mcp.Description("Text of the comment"),
+		),
+	)
+
+	// Wrap the diff comment handler to include the config
+	wrappedDiffCommentHandler := func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return CreateDiffCommentHandler(ctx, request, client, config)
+	}
+	s.AddTool(createDiffCommentTool, wrappedDiffCommentHandler)
+
+	// Create draft note tool
+	createDraftNoteTool := mcp.NewTool("create_draft_note",
+		mcp.WithDescription("Add a draft note to the pending review of a merge request without notifying anyone"),
+		withMergeRequest(),
+		withProject(),
+		withRepoPath(),
+		mcp.WithString(
+			"body",
+			mcp.Required(),
+			mcp.Description("Text of the draft note"),
+		),
+		mcp.WithString(
+			"path",
+			mcp.Description("Path of the file for an inline draft note"),
+		),
+		mcp.WithNumber(
+			"line",
+			mcp.Description("Line of an inline draft note, or the first line of a range"),
+		),
+		mcp.WithNumber(
+			"endLine",
+			mcp.Description("Last line of a multi-line range"),
+		),
+		mcp.WithString(
+			"side",
+			mcp.Description("Side of the diff the line numbers refer to: new (default) or old for removed lines"),
+			mcp.Enum(SideNew, SideOld),
+		),
+		mcp.WithString(
+			"discussionID",
+			mcp.Description("ID of a discussion thread to reply to"),
+		),
+		mcp.WithBoolean(
+			"resolveDiscussion",
+			mcp.Description("Resolve the replied-to discussion when the draft is published"),
+		),
+	)
+
+	// Wrap the draft note handlers to include the config
+	wrappedCreateDraftNoteHandler := func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return CreateDraftNoteHandler(ctx, request, client, config)
+	}
+	s.AddTool(createDraftNoteTool, wrappedCreateDraftNoteHandler)
+
+	// List draft notes tool
+	listDraftNotesTool := mcp.NewTool("list_draft_notes",
+		mcp.WithDescription("List the pending draft notes of a merge request review"),
+		withMergeRequest(),
+		withProject(),
+		withRepoPath(),
+	)
+
+	wrappedListDraftNotesHandler := func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return ListDraftNotesHandler(ctx, request, client, config)
+	}
+	s.AddTool(listDraftNotesTool, wrappedListDraftNotesHandler)
+
+	// Update draft note tool
+	updateDraftNoteTool := mcp.NewTool("update_draft_note",
+		mcp.WithDescription("Replace the text of a draft note"),
+		withMergeRequest(),
+		withProject(),
+		withRepoPath(),
+		mcp.WithNumber(
+			"draftNoteID",
+			mcp.Required(),
+			mcp.Description("ID of the draft note"),
+		),
+		mcp.WithString(
+			"body",
+			mcp.Required(),
+			mcp.Description("New text of the draft note"),
+		),
+	)
+
+	wrappedUpdateDraftNoteHandler := func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return UpdateDraftNoteHandler(ctx, request, client, config)
+	}
+	s.AddTool(updateDraftNoteTool, wrappedUpdateDraftNoteHandler)
+
+	// Delete draft note tool
+	deleteDraftNoteTool := mcp.NewTool("delete_draft_note",
+		mcp.WithDescription("Delete a draft note"),
+		withMergeRequest(),
+		withProject(),
+		withRepoPath(),
+		mcp.WithNumber(
+			"draftNoteID",
+			mcp.Required(),
+			mcp.Description("ID of the draft note"),
+		),
+	)
+
+	wrappedDeleteDraftNoteHandler := func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return DeleteDraftNoteHandler(ctx, request, client, config)
+	}
+	s.AddTool(deleteDraftNoteTool, wrappedDeleteDraftNoteHandler)
+
+	// Publish draft notes tool
+	publishDraftNotesTool := mcp.NewTool("publish_draft_notes",
+		mcp.WithDescription("Publish all pending draft notes of a merge request at once, or a single one"),
+		withMergeRequest(),
+		withProject(),
+		withRepoPath(),
+		mcp.WithNumber(
+			"draftNoteID",
+			mcp.Description("ID of a single draft note to publish; all drafts are published when omitted"),
+		),
+	)
+
+	wrappedPublishDraftNotesHandler := func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return PublishDraftNotesHandler(ctx, request, client, config)
+	}
+	s.AddTool(publishDraftNotesTool, wrappedPublishDraftNotesHandler)
+
+	// Apply suggestion tool
+	applySuggestionTool := mcp.NewTool("apply_suggestion",
+		mcp.WithDescription("Apply a reviewer's suggestion block to the local working tree"),
+		withMergeRequest(),
+		withProject(),
+		withRepoPath(),
+		mcp.WithString(
+			"discussionID",
+			mcp.Required(),
+			mcp.Description("ID of the discussion thread holding the suggestion"),
+		),
+		mcp.WithNumber(
+			"noteID",
+			mcp.Description("ID of the note holding the suggestion; defaults to the first note with suggestions"),
+		),
+		mcp.WithNumber(
+			"suggestionIndex",
+			mcp.Description("Index of the suggestion within the note (default 0)"),
+		),
+		mcp.WithBoolean(
+			"dryRun",
+			mcp.Description("Only show the diff that would be applied"),
+		),
+	)
+
+	// Wrap the suggestion handler to include the config
+	wrappedApplySuggestionHandler := func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return ApplySuggestionHandler(ctx, request, client, config)
+	}
+	s.AddTool(applySuggestionTool, wrappedApplySuggestionHandler)
+
+	// Apply remote suggestions tool
+	applyRemoteSuggestionsTool := mcp.NewTool("apply_remote_suggestions",
+		mcp.WithDescription("Let GitLab commit one or more suggestions to the merge request source branch"),
+		mcp.WithArray(
+			"suggestionIDs",
+			mcp.Required(),
+			mcp.Description("IDs of the suggestions to apply, as listed by get_merge_request_comments"),
+			mcp.Items(map[string]any{"type": "number"}),
+		),
+		mcp.WithString(
+			"commitMessage",
+			mcp.Description("Custom commit message; the project default is used when omitted"),
+		),
+	)
+
+	// Wrap the remote suggestions handler to include the config
+	wrappedApplyRemoteSuggestionsHandler := func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return ApplyRemoteSuggestionsHandler(ctx, request, client, config)
+	}
+	s.AddTool(applyRemoteSuggestionsTool, wrappedApplyRemoteSuggestionsHandler)
+
+	// Get merge request pipeline tool
+	getMergeRequestPipelineTool := mcp.NewTool("get_merge_request_pipeline",
+		mcp.WithDescription("Summarise the latest pipeline of a merge request with its failed jobs first"),
+		withMergeRequest(),
+		withProject(),
+		withRepoPath(),
+	)
+
+	// Wrap the pipeline handler to include the config
+	wrappedPipelineHandler := func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return GetMergeRequestPipelineHandler(ctx, request, client, config)
+	}
+	s.AddTool(getMergeRequestPipelineTool, wrappedPipelineHandler)
+
+	// Get job log tool
+	getJobLogTool := mcp.NewTool("get_job_log",
+		mcp.WithDescription("Get the log of a CI job, trimmed to its tail and the lines explaining failures. Pass the project printed by get_merge_request_pipeline, as pipelines of merge requests from forks and of downstream pipelines run in other projects"),
+		mcp.WithNumber(
+			"jobID",
+			mcp.Required(),
+			mcp.Description("ID of the job, as listed by get_merge_request_pipeline"),
+		),
+		mcp.WithString(
+			"project",
+			mcp.Description("ID or path of the project the job belongs to, as printed in the Project line of get_merge_request_pipeline; defaults to the configured project"),
+		),
+		mcp.WithNumber(
+			"maxBytes",
+			mcp.Description("Size budget of the returned log in bytes (default 16000)"),
+		),
+	)
+
+	// Wrap the job log handler to include the config
+	wrappedJobLogHandler := func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return GetJobLogHandler(ctx, request, client, config)
+	}
+	s.AddTool(getJobLogTool, wrappedJobLogHandler)
+}
+
+// withMergeRequest adds the optional mergeRequest argument understood by ResolveMergeRequest.
+func withMergeRequest() mcp.ToolOption {
+	return mcp.WithString(
+		"mergeRequest",
+		mcp.Description("Merge request as IID, !IID, group/project!IID, web URL or source branch; defaults to the open merge request of the current branch"),
+	)
+}
+
+// withProject adds the optional project argument understood by ResolveMergeRequest.
+func withProject() mcp.ToolOption {
+	return mcp.WithString(
+		"project",
+		mcp.Description("ID or path of the project the merge request belongs to; branch lookups search all configured projects when omitted"),
+	)
+}
+
+// withRepoPath adds the optional repoPath argument understood by repoPath.
+func withRepoPath() mcp.ToolOption {
+	return mcp.WithString(
+		"repoPath",
+		mcp.Description("Directory or file:// URI of the local git repository, e.g. a workspace root; defaults to the configured repository. Its merge requests are looked up in the project of its git remote unless project is given"),
+	)
 }
@@ -0,0 +1,322 @@
+// Package gitlabmcp provides the GitLab MCP tool functionality.
+package gitlabmcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/ondratuma/gitlab-review-mcp/pkg/git"
+	"github.com/ondratuma/gitlab-review-mcp/pkg/gitlab"
+)
+
+// referencePattern matches "123", "!123" and "group/project!123".
+var referencePattern = regexp.MustCompile(`^(?:([\w.\-/]+)?!)?(\d+)$`)
+
+// MergeRequestTarget identifies the merge request a tool call operates on.
+type MergeRequestTarget struct {
+	ProjectID string
+	IID       int
+}
+
+// MergeRequestSelector is a parsed mergeRequest tool argument. It selects a
+// merge request either by IID or by source branch.
+type MergeRequestSelector struct {
+	// ProjectID is empty for the configured project.
+	ProjectID string
+	// IID is 0 when selecting by branch.
+	IID int
+	// Branch is empty for the current branch.
+	Branch string
+}
+
+// ParseMergeRequestSelector parses an IID ("123"), a reference ("!123" or
+// "group/project!123"), a merge request web URL or a source branch name. An
+// empty value selects the current branch. The path of gitLabURL is stripped
+// from web URLs of instances served under a relative URL.
+func ParseMergeRequestSelector(value, gitLabURL string) (MergeRequestSelector, error) {
+	value = strings.TrimSpace(value)
+
+	if match := referencePattern.FindStringSubmatch(value); match != nil {
+		iid, err := strconv.Atoi(match[2])
+		if err != nil {
+			return MergeRequestSelector{}, err
+		}
+		return MergeRequestSelector{ProjectID: match[1], IID: iid}, nil
+	}
+
+	if strings.Contains(value, "://") {
+		return parseMergeRequestURL(value, gitLabURL)
+	}
+
+	return MergeRequestSelector{Branch: value}, nil
+}
+
+// parseMergeRequestURL parses a web URL such as
+// https://gitlab.com/group/project/-/merge_requests/123/diffs. URLs of other
+// instances than gitLabURL, gitlab.com when empty, are rejected.
+func parseMergeRequestURL(value, gitLabURL string) (MergeRequestSelector, error) {
+	u, err := url.Parse(value)
+	if err != nil {
+		return MergeRequestSelector{}, err
+	}
+
+	projectPath, rest, ok := strings.Cut(u.Path, "/-/merge_requests/")
+	if !ok {
+		return MergeRequestSelector{}, fmt.Errorf("%s is not a merge request URL", value)
+	}
+
+	iidPart, _, _ := strings.Cut(rest, "/")
+	iid, err := strconv.Atoi(iidPart)
+	if err != nil {
+		return MergeRequestSelector{}, fmt.Errorf("%s is not a merge request URL", value)
+	}
+
+	// The same path may be another merge request on the configured instance
+	if gitLabURL == "" {
+		gitLabURL = DefaultGitLabURL
+	}
+	instance, err := url.Parse(gitLabURL)
+	if err != nil {
+		return MergeRequestSelector{}, err
+	}
+	if !strings.EqualFold(instance.Host, u.Host) {
+		return MergeRequestSelector{}, fmt.Errorf("%s is not on the configured GitLab instance %s", value, gitLabURL)
+	}
+
+	projectPath = strings.Trim(projectPath, "/")
+	if prefix := strings.Trim(instance.Path, "/"); prefix != "" {
+		projectPath = strings.TrimPrefix(projectPath, prefix+"/")
+	}
+
+	return MergeRequestSelector{ProjectID: projectPath, IID: iid}, nil
+}
+
+// ResolveMergeRequest determines the merge request a tool request refers to,
+// defaulting to the open merge request of the current branch.
+func ResolveMergeRequest(ctx context.Context, request mcp.CallToolRequest, client *gitlab.Client, config Config) (MergeRequestTarget, error) {
+	var selector MergeRequestSelector
+
+	args := request.GetArguments()
+	value, ok := args["mergeRequest"]
+	if !ok {
+		// Older clients pass the numeric mergeRequestIID argument
+		value = args["mergeRequestIID"]
+	}
+
+	switch v := value.(type) {
+	case float64:
+		selector.IID = int(v)
+	case string:
+		parsed, err := ParseMergeRequestSelector(v, config.GitLabURL)
+		if err != nil {
+			return MergeRequestTarget{}, err
+		}
+		selector = parsed
+	case nil:
+	default:
+		return MergeRequestTarget{}, fmt.Errorf("invalid mergeRequest argument %v", v)
+	}
+
+	// An explicit project restricts branch lookups to that project
+	project := selector.ProjectID
+	if project == "" {
+		project = requestProject(request, config)
+	}
+
+	if selector.IID != 0 {
+		if project == "" {
+			project = config.ProjectID
+		}
+		if project == "" {
+			return MergeRequestTarget{}, fmt.Errorf("no default project is configured, pass project or a group/project!IID reference")
+		}
+		return MergeRequestTarget{ProjectID: project, IID: selector.IID}, nil
+	}
+
+	scope := config.searchScope(project)
+
+	var mrs []foundMergeRequest
+	var source string
+	var err error
+	if selector.Branch != "" {
+		source = "branch " + selector.Branch
+		mrs, err = scope.mergeRequestsByBranch(ctx, client, selector.Branch)
+	} else {
+		mrs, source, err = currentMergeRequests(ctx, client, scope, repoPath(request, config))
+	}
+	if err != nil {
+		return MergeRequestTarget{}, err
+	}
+
+	var target MergeRequestTarget
+	var open []string
+	for _, mr := range mrs {
+		if mr.State == "opened" {
+			open = append(open, mr.Reference())
+			target = MergeRequestTarget{ProjectID: mr.Project, IID: mr.IID}
+		}
+	}
+
+	switch len(open) {
+	case 0:
+		return MergeRequestTarget{}, fmt.Errorf("no open merge request found for %s", source)
+	case 1:
+		return target, nil
+	default:
+		return MergeRequestTarget{}, fmt.Errorf("%s has several open merge requests (%s), pass mergeRequest to choose one", source, strings.Join(open, ", "))
+	}
+}
+
+// requestProject returns the project named by the project argument of a tool
+// request. Without it, the project of a repository passed as repoPath is
+// detected from its git remote, so each repository of a multi-root workspace
+// is looked up in its own project. An empty string selects the configured
+// projects, also when the detection fails.
+func requestProject(request mcp.CallToolRequest, config Config) string {
+	if project := request.GetString("project", ""); project != "" {
+		return project
+	}
+	if request.GetString("repoPath", "") == "" {
+		return ""
+	}
+
+	project, err := DetectProjectID(repoPath(request, config), config.GitRemote, config.GitLabURL)
+	if err != nil {
+		slog.Debug("could not detect the project of repoPath, searching the configured projects", "error", err)
+		return ""
+	}
+	return project
+}
+
+// foundMergeRequest is a merge request found by a branch or commit lookup,
+// along with the project it was found in.
+type foundMergeRequest struct {
+	gitlab.MergeRequest
+	Project string
+}
+
+// projectScope lists where branch and commit lookups search for merge requests.
+type projectScope struct {
+	Projects []string
+	// Group is searched by branch lookups only, as GitLab cannot look up
+	// merge requests by commit across a group.
+	Group string
+}
+
+// searchScope returns the projects searched for merge requests: the given
+// project only, or all configured projects and the configured group.
+func (c Config) searchScope(project string) projectScope {
+	if project != "" {
+		return projectScope{Projects: []string{project}}
+	}
+
+	var scope projectScope
+	seen := make(map[string]bool)
+	for _, p := range append([]string{c.ProjectID}, c.Projects...) {
+		if p != "" && !seen[p] {
+			seen[p] = true
+			scope.Projects = append(scope.Projects, p)
+		}
+	}
+	scope.Group = c.Group
+
+	return scope
+}
+
+// mergeRequestsByBranch returns the merge requests of a source branch in all
+// projects of the scope.
+func (s projectScope) mergeRequestsByBranch(ctx context.Context, client *gitlab.Client, branch string) ([]foundMergeRequest, error) {
+	var found []foundMergeRequest
+	for _, project := range s.Projects {
+		mrs, err := client.GetMergeRequestsBySourceBranch(ctx, project, branch)
+		if err != nil {
+			return nil, fmt.Errorf("project %s: %w", project, err)
+		}
+		found = appendFound(found, project, mrs)
+	}
+
+	if s.Group != "" {
+		mrs, err := client.GetGroupMergeRequestsBySourceBranch(ctx, s.Group, branch)
+		if err != nil {
+			return nil, fmt.Errorf("group %s: %w", s.Group, err)
+		}
+		found = appendFound(found, "", mrs)
+	}
+
+	return found, nil
+}
+
+// mergeRequestsByCommit returns the merge requests containing a commit in all
+// projects of the scope.
+func (s projectScope) mergeRequestsByCommit(ctx context.Context, client *gitlab.Client, sha string) ([]foundMergeRequest, error) {
+	if len(s.Projects) == 0 {
+		return nil, fmt.Errorf("HEAD is detached and merge requests cannot be looked up by commit in a group, pass project or mergeRequest")
+	}
+
+	var found []foundMergeRequest
+	for _, project := range s.Projects {
+		mrs, err := client.GetMergeRequestsByCommit(ctx, project, sha)
+		if err != nil {
+			return nil, fmt.Errorf("project %s: %w", project, err)
+		}
+		found = appendFound(found, project, mrs)
+	}
+
+	return found, nil
+}
+
+// appendFound appends the merge requests found in project, skipping those
+// already found elsewhere. An empty project stands for the project_id
+// reported by GitLab, as for group lookups.
+func appendFound(found []foundMergeRequest, project string, mrs []gitlab.MergeRequest) []foundMergeRequest {
+	for _, mr := range mrs {
+		duplicate := false
+		for _, f := range found {
+			if mr.ID != 0 && f.ID == mr.ID {
+				duplicate = true
+				break
+			}
+		}
+		if duplicate {
+			continue
+		}
+
+		p := project
+		if p == "" {
+			p = strconv.Itoa(mr.ProjectID)
+		}
+		found = append(found, foundMergeRequest{MergeRequest: mr, Project: p})
+	}
+
+	return found
+}
+
+// currentMergeRequests returns the merge requests of the current branch of the
+// repository at dir. When HEAD is detached, as in CI checkouts, the merge
+// requests containing the HEAD commit are returned instead. The second value
+// describes what was looked up.
+func currentMergeRequests(ctx context.Context, client *gitlab.Client, scope projectScope, dir string) ([]foundMergeRequest, string, error) {
+	branch, err := git.GetCurrentBranch(dir)
+	if err == nil {
+		mrs, err := scope.mergeRequestsByBranch(ctx, client, branch)
+		return mrs, "branch " + branch, err
+	}
+	if !errors.Is(err, git.ErrDetachedHead) {
+		return nil, "", err
+	}
+
+	sha, err := git.GetHeadCommit(dir)
+	if err != nil {
+		return nil, "", err
+	}
+
+	mrs, err := scope.mergeRequestsByCommit(ctx, client, sha)
+	return mrs, "commit " + sha, err
+}
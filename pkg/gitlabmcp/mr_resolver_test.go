@@ -0,0 +1,336 @@
+package gitlabmcp
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/ondratuma/gitlab-review-mcp/pkg/git"
+	"github.com/ondratuma/gitlab-review-mcp/pkg/gitlab"
+)
+
+// mockHTTPClient returns a fixed response body for every request, or the
+// body for the request path when bodies is set
+type mockHTTPClient struct {
+	body     string
+	bodies   map[string]string
+	requests []*http.Request
+}
+
+// Do implements the gitlab.HTTPClient interface
+func (m *mockHTTPClient) Do(req *http.Request) (*http.Response, error) {
+	m.requests = append(m.requests, req)
+
+	body := m.body
+	if m.bodies != nil {
+		body = m.bodies[req.URL.EscapedPath()]
+	}
+
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Body:       io.NopCloser(bytes.NewBufferString(body)),
+		Header:     http.Header{},
+	}, nil
+}
+
+func TestParseMergeRequestSelector(t *testing.T) {
+	tests := []struct {
+		name      string
+		value     string
+		gitLabURL string
+		expected  MergeRequestSelector
+		expectErr bool
+	}{
+		{name: "empty", value: "", expected: MergeRequestSelector{}},
+		{name: "IID", value: "123", expected: MergeRequestSelector{IID: 123}},
+		{name: "short reference", value: "!123", expected: MergeRequestSelector{IID: 123}},
+		{name: "full reference", value: "group/sub/project!42", expected: MergeRequestSelector{ProjectID: "group/sub/project", IID: 42}},
+		{
+			name:     "web URL",
+			value:    "https://gitlab.com/group/project/-/merge_requests/5/diffs",
+			expected: MergeRequestSelector{ProjectID: "group/project", IID: 5},
+		},
+		{
+			name:      "web URL of relative URL instance",
+			value:     "https://example.com/gitlab/group/project/-/merge_requests/5",
+			gitLabURL: "https://example.com/gitlab",
+			expected:  MergeRequestSelector{ProjectID: "group/project", IID: 5},
+		},
+		{name: "branch", value: "feature/login", expected: MergeRequestSelector{Branch: "feature/login"}},
+		{name: "not a merge request URL", value: "https://gitlab.com/group/project/-/issues/5", expectErr: true},
+		{
+			name:      "web URL of another instance",
+			value:     "https://other.example.com/group/project/-/merge_requests/5",
+			gitLabURL: "https://gitlab.example.com",
+			expectErr: true,
+		},
+		{name: "web URL of another instance than gitlab.com", value: "https://other.example.com/g/p/-/merge_requests/5", expectErr: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			selector, err := ParseMergeRequestSelector(tc.value, tc.gitLabURL)
+			if tc.expectErr {
+				if err == nil {
+					t.Errorf("expected an error, got %+v", selector)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if selector != tc.expected {
+				t.Errorf("expected %+v, got %+v", tc.expected, selector)
+			}
+		})
+	}
+}
+
+func TestResolveMergeRequest(t *testing.T) {
+	originalGetCurrentBranch := git.GetCurrentBranch
+	defer func() { git.GetCurrentBranch = originalGetCurrentBranch }()
+	originalGetHeadCommit := git.GetHeadCommit
+	defer func() { git.GetHeadCommit = originalGetHeadCommit }()
+	git.GetHeadCommit = func(dir string) (string, error) { return "abc123", nil }
+
+	config := Config{ProjectID: "12345", GitLabURL: DefaultGitLabURL}
+
+	tests := []struct {
+		name        string
+		arguments   map[string]any
+		detached    bool
+		response    string
+		expected    MergeRequestTarget
+		expectedErr string
+	}{
+		{
+			name:      "IID",
+			arguments: map[string]any{"mergeRequest": "!7"},
+			expected:  MergeRequestTarget{ProjectID: "12345", IID: 7},
+		},
+		{
+			name:      "legacy numeric argument",
+			arguments: map[string]any{"mergeRequestIID": float64(8)},
+			expected:  MergeRequestTarget{ProjectID: "12345", IID: 8},
+		},
+		{
+			name:      "project argument",
+			arguments: map[string]any{"mergeRequest": "7", "project": "group/other"},
+			expected:  MergeRequestTarget{ProjectID: "group/other", IID: 7},
+		},
+		{
+			name:      "other project",
+			arguments: map[string]any{"mergeRequest": "group/project!9"},
+			expected:  MergeRequestTarget{ProjectID: "group/project", IID: 9},
+		},
+		{
+			name:     "current branch",
+			response: `[{"iid":3,"state":"merged"},{"iid":4,"state":"opened"}]`,
+			expected: MergeRequestTarget{ProjectID: "12345", IID: 4},
+		},
+		{
+			name:     "detached HEAD",
+			detached: true,
+			response: `[{"iid":5,"state":"opened"}]`,
+			expected: MergeRequestTarget{ProjectID: "12345", IID: 5},
+		},
+		{
+			name:        "no open merge request",
+			response:    `[{"iid":3,"state":"closed"}]`,
+			expectedErr: "no open merge request found for branch feature",
+		},
+		{
+			name:        "several open merge requests",
+			arguments:   map[string]any{"mergeRequest": "other"},
+			response:    `[{"iid":3,"state":"opened"},{"iid":4,"state":"opened"}]`,
+			expectedErr: "branch other has several open merge requests (!3, !4)",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			git.GetCurrentBranch = func(dir string) (string, error) {
+				if tc.detached {
+					return "", git.ErrDetachedHead
+				}
+				return "feature", nil
+			}
+
+			httpClient := &mockHTTPClient{body: tc.response}
+			client := gitlab.NewClient("test-token")
+			client.HTTPClient = httpClient
+
+			request := mcp.CallToolRequest{}
+			request.Params.Arguments = tc.arguments
+
+			target, err := ResolveMergeRequest(context.Background(), request, client, config)
+			if tc.expectedErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.expectedErr) {
+					t.Errorf("expected error containing %q, got %v", tc.expectedErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if target != tc.expected {
+				t.Errorf("expected %+v, got %+v", tc.expected, target)
+			}
+			if tc.detached && !strings.HasSuffix(httpClient.requests[0].URL.Path, "/repository/commits/abc123/merge_requests") {
+				t.Errorf("expected a lookup by commit, got %s", httpClient.requests[0].URL.Path)
+			}
+		})
+	}
+}
+
+func TestResolveMergeRequestRepoPath(t *testing.T) {
+	originalGetCurrentBranch := git.GetCurrentBranch
+	defer func() { git.GetCurrentBranch = originalGetCurrentBranch }()
+	git.GetCurrentBranch = func(dir string) (string, error) { return "fix-typo", nil }
+	originalGetRemoteURL := git.GetRemoteURL
+	defer func() { git.GetRemoteURL = originalGetRemoteURL }()
+	git.GetRemoteURL = func(dir, remote string) (string, error) {
+		switch dir {
+		case "/work/app":
+			return "git@gitlab.com:group/app.git", nil
+		case "/work/lib":
+			return "git@gitlab.com:group/lib.git", nil
+		}
+		return "", errors.New("not a git repository")
+	}
+
+	// The configured project was detected from /work/app, and both projects
+	// have an open merge request for the branch
+	config := Config{ProjectID: "group/app", GitRemote: DefaultGitRemote, GitLabURL: DefaultGitLabURL, RepoPath: "/work/app"}
+	bodies := map[string]string{
+		"/api/v4/projects/group%2Fapp/merge_requests": `[{"id":1,"iid":1,"state":"opened"}]`,
+		"/api/v4/projects/group%2Flib/merge_requests": `[{"id":2,"iid":4,"state":"opened"}]`,
+	}
+
+	tests := []struct {
+		name      string
+		arguments map[string]any
+		expected  MergeRequestTarget
+	}{
+		{
+			name:      "repository with another remote",
+			arguments: map[string]any{"repoPath": "/work/lib"},
+			expected:  MergeRequestTarget{ProjectID: "group/lib", IID: 4},
+		},
+		{
+			name:      "IID in a repository with another remote",
+			arguments: map[string]any{"mergeRequest": "!9", "repoPath": "/work/lib"},
+			expected:  MergeRequestTarget{ProjectID: "group/lib", IID: 9},
+		},
+		{
+			name:      "project argument wins",
+			arguments: map[string]any{"repoPath": "/work/lib", "project": "group/app"},
+			expected:  MergeRequestTarget{ProjectID: "group/app", IID: 1},
+		},
+		{
+			name:      "undetectable repository",
+			arguments: map[string]any{"repoPath": "/work/scratch"},
+			expected:  MergeRequestTarget{ProjectID: "group/app", IID: 1},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			client := gitlab.NewClient("test-token")
+			client.HTTPClient = &mockHTTPClient{bodies: bodies}
+
+			request := mcp.CallToolRequest{}
+			request.Params.Arguments = tc.arguments
+
+			target, err := ResolveMergeRequest(context.Background(), request, client, config)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if target != tc.expected {
+				t.Errorf("expected %+v, got %+v", tc.expected, target)
+			}
+		})
+	}
+}
+
+func TestResolveMergeRequestAcrossProjects(t *testing.T) {
+	originalGetCurrentBranch := git.GetCurrentBranch
+	defer func() { git.GetCurrentBranch = originalGetCurrentBranch }()
+	git.GetCurrentBranch = func(dir string) (string, error) { return "feature", nil }
+
+	config := Config{ProjectID: "group/app", Projects: []string{"group/lib", "group/app"}, Group: "group"}
+
+	tests := []struct {
+		name        string
+		arguments   map[string]any
+		bodies      map[string]string
+		expected    MergeRequestTarget
+		expectedErr string
+	}{
+		{
+			name: "found in another project",
+			bodies: map[string]string{
+				"/api/v4/projects/group%2Fapp/merge_requests": `[]`,
+				"/api/v4/projects/group%2Flib/merge_requests": `[{"id":2,"iid":4,"project_id":20,"state":"opened"}]`,
+				"/api/v4/groups/group/merge_requests":         `[{"id":2,"iid":4,"project_id":20,"state":"opened"}]`,
+			},
+			expected: MergeRequestTarget{ProjectID: "group/lib", IID: 4},
+		},
+		{
+			name: "found in the group",
+			bodies: map[string]string{
+				"/api/v4/projects/group%2Fapp/merge_requests": `[]`,
+				"/api/v4/projects/group%2Flib/merge_requests": `[]`,
+				"/api/v4/groups/group/merge_requests":         `[{"id":3,"iid":6,"project_id":30,"state":"opened"}]`,
+			},
+			expected: MergeRequestTarget{ProjectID: "30", IID: 6},
+		},
+		{
+			name: "open in several projects",
+			bodies: map[string]string{
+				"/api/v4/projects/group%2Fapp/merge_requests": `[{"id":1,"iid":1,"state":"opened","references":{"full":"group/app!1"}}]`,
+				"/api/v4/projects/group%2Flib/merge_requests": `[{"id":2,"iid":4,"state":"opened","references":{"full":"group/lib!4"}}]`,
+				"/api/v4/groups/group/merge_requests":         `[]`,
+			},
+			expectedErr: "branch feature has several open merge requests (group/app!1, group/lib!4)",
+		},
+		{
+			name:      "restricted to one project",
+			arguments: map[string]any{"project": "group/app"},
+			bodies: map[string]string{
+				"/api/v4/projects/group%2Fapp/merge_requests": `[{"id":1,"iid":1,"state":"opened"}]`,
+				"/api/v4/projects/group%2Flib/merge_requests": `[{"id":2,"iid":4,"state":"opened"}]`,
+			},
+			expected: MergeRequestTarget{ProjectID: "group/app", IID: 1},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			client := gitlab.NewClient("test-token")
+			client.HTTPClient = &mockHTTPClient{bodies: tc.bodies}
+
+			request := mcp.CallToolRequest{}
+			request.Params.Arguments = tc.arguments
+
+			target, err := ResolveMergeRequest(context.Background(), request, client, config)
+			if tc.expectedErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.expectedErr) {
+					t.Errorf("expected error containing %q, got %v", tc.expectedErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if target != tc.expected {
+				t.Errorf("expected %+v, got %+v", tc.expected, target)
+			}
+		})
+	}
+}
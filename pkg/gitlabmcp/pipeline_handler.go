@@ -0,0 +1,161 @@
+// Package gitlabmcp provides the GitLab MCP tool functionality.
+package gitlabmcp
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/ondratuma/gitlab-review-mcp/pkg/gitlab"
+)
+
+// jobFailed reports whether a job failed. Jobs allowed to fail still count,
+// as their errors are usually worth a look.
+func jobFailed(job gitlab.Job) bool {
+	return job.Status == "failed"
+}
+
+// formatDuration formats a duration in seconds, rounded to whole seconds.
+func formatDuration(seconds float64) string {
+	return time.Duration(seconds * float64(time.Second)).Round(time.Second).String()
+}
+
+// formatJob formats a single job as a list item. Trigger jobs are followed by
+// the pipeline they started, downstream, which is nil for other jobs.
+func formatJob(job gitlab.Job, downstream *gitlab.Pipeline) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("- %s (stage %s, job %d): %s", job.Name, job.Stage, job.ID, job.Status))
+	if job.AllowFailure && jobFailed(job) {
+		sb.WriteString(" (allowed to fail)")
+	}
+	if job.FailureReason != "" {
+		sb.WriteString(fmt.Sprintf(", reason: %s", job.FailureReason))
+	}
+	if job.Duration != nil {
+		sb.WriteString(fmt.Sprintf(", took %s", formatDuration(*job.Duration)))
+	}
+	if downstream != nil {
+		sb.WriteString(fmt.Sprintf("\n  Downstream pipeline #%d", downstream.ID))
+		if downstream.ProjectID != 0 {
+			sb.WriteString(fmt.Sprintf(" in project %d", downstream.ProjectID))
+		}
+		sb.WriteString(fmt.Sprintf(": %s", downstream.Status))
+		if downstream.WebURL != "" {
+			sb.WriteString(fmt.Sprintf("\n  %s", downstream.WebURL))
+		}
+	}
+	if job.WebURL != "" {
+		sb.WriteString(fmt.Sprintf("\n  %s", job.WebURL))
+	}
+	sb.WriteString("\n")
+
+	return sb.String()
+}
+
+// FormatPipeline summarises a pipeline with its jobs and trigger jobs, listing
+// failed jobs first.
+func FormatPipeline(pipeline gitlab.Pipeline, jobs []gitlab.Job, bridges []gitlab.Bridge) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Pipeline #%d: %s\n", pipeline.ID, pipeline.Status))
+	// Jobs are looked up in this project, which differs for merge requests from forks
+	if pipeline.ProjectID != 0 {
+		sb.WriteString(fmt.Sprintf("Project: %d\n", pipeline.ProjectID))
+	}
+	sb.WriteString(fmt.Sprintf("Ref: %s at %s\n", pipeline.Ref, pipeline.SHA))
+	if pipeline.Source != "" {
+		sb.WriteString(fmt.Sprintf("Source: %s\n", pipeline.Source))
+	}
+	if pipeline.Duration != nil {
+		sb.WriteString(fmt.Sprintf("Duration: %s\n", formatDuration(float64(*pipeline.Duration))))
+	}
+	sb.WriteString(fmt.Sprintf("URL: %s\n", pipeline.WebURL))
+
+	// Jobs and trigger jobs are listed together, jobs without a downstream
+	// pipeline. Failed jobs first, then in the order they were created.
+	sorted := make([]gitlab.Bridge, 0, len(jobs)+len(bridges))
+	for _, job := range jobs {
+		sorted = append(sorted, gitlab.Bridge{Job: job})
+	}
+	sorted = append(sorted, bridges...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if jobFailed(sorted[i].Job) != jobFailed(sorted[j].Job) {
+			return jobFailed(sorted[i].Job)
+		}
+		return sorted[i].ID < sorted[j].ID
+	})
+
+	counts := make(map[string]int)
+	for _, job := range sorted {
+		counts[job.Status]++
+	}
+	statuses := make([]string, 0, len(counts))
+	for status, count := range counts {
+		statuses = append(statuses, fmt.Sprintf("%d %s", count, status))
+	}
+	sort.Strings(statuses)
+	sb.WriteString(fmt.Sprintf("\nJobs: %d", len(sorted)))
+	if len(statuses) > 0 {
+		sb.WriteString(fmt.Sprintf(" (%s)", strings.Join(statuses, ", ")))
+	}
+	sb.WriteString("\n")
+
+	failedHeader, otherHeader := false, false
+	for _, job := range sorted {
+		if jobFailed(job.Job) && !failedHeader {
+			sb.WriteString("\nFailed jobs:\n")
+			failedHeader = true
+		}
+		if !jobFailed(job.Job) && !otherHeader {
+			sb.WriteString("\nOther jobs:\n")
+			otherHeader = true
+		}
+		sb.WriteString(formatJob(job.Job, job.DownstreamPipeline))
+	}
+
+	return sb.String()
+}
+
+// GetMergeRequestPipelineHandler handles the getMergeRequestPipeline tool request.
+func GetMergeRequestPipelineHandler(ctx context.Context, request mcp.CallToolRequest, client *gitlab.Client, config Config) (*mcp.CallToolResult, error) {
+	mr, err := ResolveMergeRequest(ctx, request, client, config)
+	if err != nil {
+		return toolError(err), nil
+	}
+
+	latest, err := client.GetLatestMergeRequestPipeline(ctx, mr.ProjectID, mr.IID)
+	if err != nil {
+		return toolError(err), nil
+	}
+	if latest == nil {
+		return mcp.NewToolResultText(fmt.Sprintf("No pipelines found for Merge Request !%d", mr.IID)), nil
+	}
+
+	// Pipelines of merge requests from forks can run in the fork
+	projectID := mr.ProjectID
+	if latest.ProjectID != 0 {
+		projectID = strconv.Itoa(latest.ProjectID)
+	}
+
+	// The list lacks the duration
+	pipeline, err := client.GetPipeline(ctx, projectID, latest.ID)
+	if err != nil {
+		return toolError(err), nil
+	}
+
+	jobs, err := client.GetPipelineJobs(ctx, projectID, pipeline.ID)
+	if err != nil {
+		return toolError(err), nil
+	}
+
+	bridges, err := client.GetPipelineBridges(ctx, projectID, pipeline.ID)
+	if err != nil {
+		return toolError(err), nil
+	}
+
+	text := fmt.Sprintf("Latest pipeline of Merge Request !%d\n%s", mr.IID, FormatPipeline(*pipeline, jobs, bridges))
+	return mcp.NewToolResultText(text), nil
+}
@@ -0,0 +1,112 @@
+package gitlabmcp
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/ondratuma/gitlab-review-mcp/pkg/gitlab"
+)
+
+func TestFormatPipeline(t *testing.T) {
+	duration := 305
+	buildDuration := 30.4
+	testDuration := 62.0
+	pipeline := gitlab.Pipeline{
+		ID:        100,
+		ProjectID: 12,
+		Status:    "failed",
+		Ref:       "refs/merge-requests/7/head",
+		SHA:       "abc123",
+		Duration:  &duration,
+		WebURL:    "https://gitlab.com/group/project/-/pipelines/100",
+	}
+	jobs := []gitlab.Job{
+		{ID: 3, Name: "deploy", Stage: "deploy", Status: "skipped"},
+		{ID: 2, Name: "test", Stage: "test", Status: "failed", FailureReason: "script_failure", Duration: &testDuration, WebURL: "https://gitlab.com/group/project/-/jobs/2"},
+		{ID: 1, Name: "build", Stage: "build", Status: "success", Duration: &buildDuration},
+		{ID: 4, Name: "lint", Stage: "test", Status: "failed", AllowFailure: true},
+	}
+	bridges := []gitlab.Bridge{
+		{
+			Job: gitlab.Job{ID: 5, Name: "e2e", Stage: "test", Status: "failed", FailureReason: "downstream_pipeline_creation_failed"},
+		},
+		{
+			Job:                gitlab.Job{ID: 6, Name: "docs", Stage: "deploy", Status: "success"},
+			DownstreamPipeline: &gitlab.Pipeline{ID: 200, ProjectID: 34, Status: "success", WebURL: "https://gitlab.com/group/docs/-/pipelines/200"},
+		},
+		{
+			Job:                gitlab.Job{ID: 7, Name: "child", Stage: "test", Status: "failed"},
+			DownstreamPipeline: &gitlab.Pipeline{ID: 201, Status: "failed", WebURL: "https://gitlab.com/group/project/-/pipelines/201"},
+		},
+	}
+
+	expected := `Pipeline #100: failed
+Project: 12
+Ref: refs/merge-requests/7/head at abc123
+Duration: 5m5s
+URL: https://gitlab.com/group/project/-/pipelines/100
+
+Jobs: 7 (1 skipped, 2 success, 4 failed)
+
+Failed jobs:
+- test (stage test, job 2): failed, reason: script_failure, took 1m2s
+  https://gitlab.com/group/project/-/jobs/2
+- lint (stage test, job 4): failed (allowed to fail)
+- e2e (stage test, job 5): failed, reason: downstream_pipeline_creation_failed
+- child (stage test, job 7): failed
+  Downstream pipeline #201: failed
+  https://gitlab.com/group/project/-/pipelines/201
+
+Other jobs:
+- build (stage build, job 1): success, took 30s
+- deploy (stage deploy, job 3): skipped
+- docs (stage deploy, job 6): success
+  Downstream pipeline #200 in project 34: success
+  https://gitlab.com/group/docs/-/pipelines/200
+`
+	actual := FormatPipeline(pipeline, jobs, bridges)
+	if actual != expected {
+		t.Errorf("unexpected output:\n%s\nexpected:\n%s", actual, expected)
+	}
+
+	// Without jobs only the pipeline is shown
+	actual = FormatPipeline(gitlab.Pipeline{ID: 1, Status: "running"}, nil, nil)
+	if strings.Contains(actual, "Failed jobs") || !strings.Contains(actual, "Jobs: 0\n") {
+		t.Errorf("unexpected output for a pipeline without jobs:\n%s", actual)
+	}
+}
+
+func TestGetMergeRequestPipelineHandler(t *testing.T) {
+	// The pipeline of a merge request from a fork runs in the fork, project 456
+	httpClient := &mockHTTPClient{bodies: map[string]string{
+		"/api/v4/projects/123/merge_requests/7":           `{"iid":7,"project_id":123}`,
+		"/api/v4/projects/123/merge_requests/7/pipelines": `[{"id":101,"project_id":456,"status":"failed"}]`,
+		"/api/v4/projects/456/pipelines/101":              `{"id":101,"project_id":456,"status":"failed"}`,
+		"/api/v4/projects/456/pipelines/101/jobs":         `[{"id":1,"name":"build","stage":"build","status":"success"}]`,
+		"/api/v4/projects/456/pipelines/101/bridges":      `[{"id":2,"name":"e2e","stage":"test","status":"failed","downstream_pipeline":{"id":300,"status":"failed"}}]`,
+	}}
+	client := gitlab.NewClient("test-token")
+	client.HTTPClient = httpClient
+
+	request := mcp.CallToolRequest{}
+	request.Params.Arguments = map[string]any{"mergeRequest": "!7"}
+
+	result, err := GetMergeRequestPipelineHandler(context.Background(), request, client, Config{ProjectID: "123"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	text := result.Content[0].(mcp.TextContent).Text
+	if result.IsError || !strings.Contains(text, "Project: 456\n") ||
+		!strings.Contains(text, "- e2e (stage test, job 2): failed\n  Downstream pipeline #300: failed") {
+		t.Errorf("unexpected result: %s", text)
+	}
+
+	// Only the latest pipeline is requested
+	for _, req := range httpClient.requests {
+		if req.URL.Path == "/api/v4/projects/123/merge_requests/7/pipelines" && req.URL.Query().Get("per_page") != "1" {
+			t.Errorf("expected a single pipeline to be requested, got %s", req.URL)
+		}
+	}
+}
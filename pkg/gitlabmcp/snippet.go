@@ -0,0 +1,183 @@
+// Package gitlabmcp provides the GitLab MCP tool functionality.
+package gitlabmcp
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/ondratuma/gitlab-review-mcp/pkg/git"
+	"github.com/ondratuma/gitlab-review-mcp/pkg/gitlab"
+)
+
+// DefaultContextLines is the number of lines shown around a commented line.
+const DefaultContextLines = 3
+
+// diffSource loads the merge request diffs as of a given head commit,
+// fetching every version at most once.
+type diffSource struct {
+	client    *gitlab.Client
+	projectID string
+	mrIID     int
+	versions  []gitlab.MergeRequestVersion
+	diffs     map[string][]gitlab.MergeRequestDiff
+}
+
+// newDiffSource creates a diffSource for the given merge request.
+func newDiffSource(client *gitlab.Client, projectID string, mrIID int) *diffSource {
+	return &diffSource{
+		client:    client,
+		projectID: projectID,
+		mrIID:     mrIID,
+		diffs:     make(map[string][]gitlab.MergeRequestDiff),
+	}
+}
+
+// FileDiff returns the diff of path in the merge request version whose head is headSHA.
+func (s *diffSource) FileDiff(ctx context.Context, headSHA, path string) (*gitlab.MergeRequestDiff, error) {
+	diffs, ok := s.diffs[headSHA]
+	if !ok {
+		if s.versions == nil {
+			versions, err := s.client.GetMergeRequestVersions(ctx, s.projectID, s.mrIID)
+			if err != nil {
+				return nil, err
+			}
+			s.versions = versions
+		}
+
+		versionID := -1
+		for _, version := range s.versions {
+			if version.HeadCommitSHA == headSHA {
+				versionID = version.ID
+				break
+			}
+		}
+		if versionID == -1 {
+			return nil, fmt.Errorf("no merge request version with head commit %s", headSHA)
+		}
+
+		version, err := s.client.GetMergeRequestVersion(ctx, s.projectID, s.mrIID, versionID)
+		if err != nil {
+			return nil, err
+		}
+		diffs = version.Diffs
+		s.diffs[headSHA] = diffs
+	}
+
+	for i := range diffs {
+		if diffs[i].NewPath == path || diffs[i].OldPath == path {
+			return &diffs[i], nil
+		}
+	}
+
+	return nil, fmt.Errorf("%s is not part of the diff at %s", path, headSHA)
+}
+
+// LineRef identifies a line of a diff. Removed lines only have an OldLine,
+// added and unchanged lines are identified by their NewLine.
+type LineRef struct {
+	OldLine int
+	NewLine int
+}
+
+// matches reports whether the diff line is the referenced line.
+func (r LineRef) matches(line git.DiffLine) bool {
+	if r.NewLine != 0 {
+		return line.NewLine == r.NewLine
+	}
+	return line.NewLine == 0 && line.OldLine == r.OldLine
+}
+
+// String formats the reference as a line number, marking removed lines.
+func (r LineRef) String() string {
+	if r.NewLine != 0 {
+		return fmt.Sprintf("%d", r.NewLine)
+	}
+	return fmt.Sprintf("%d (removed)", r.OldLine)
+}
+
+// PositionLines returns the first and last line a diff note is anchored to.
+// It returns false for notes that are not anchored to lines, such as image notes.
+func PositionLines(position *gitlab.NotePosition) (LineRef, LineRef, bool) {
+	single := LineRef{}
+	if position.OldLine != nil {
+		single.OldLine = *position.OldLine
+	}
+	if position.NewLine != nil {
+		single.NewLine = *position.NewLine
+	}
+	if single.OldLine == 0 && single.NewLine == 0 {
+		return LineRef{}, LineRef{}, false
+	}
+
+	if position.LineRange == nil || position.LineRange.Start == nil || position.LineRange.End == nil {
+		return single, single, true
+	}
+
+	toRef := func(p *gitlab.LinePosition) LineRef {
+		ref := LineRef{}
+		if p.OldLine != nil {
+			ref.OldLine = *p.OldLine
+		}
+		if p.NewLine != nil {
+			ref.NewLine = *p.NewLine
+		}
+		return ref
+	}
+
+	return toRef(position.LineRange.Start), toRef(position.LineRange.End), true
+}
+
+// CodeSnippet returns up to contextLines lines of the diff on either side of
+// the lines from start to end, marking the commented lines with ">". The
+// snippet never extends beyond the hunk containing the lines.
+func CodeSnippet(diff string, start, end LineRef, contextLines int) (string, error) {
+	hunks, err := git.ParseHunks(diff)
+	if err != nil {
+		return "", err
+	}
+
+	for _, hunk := range hunks {
+		lines := hunk.NumberedLines()
+
+		first, last := -1, -1
+		for i, line := range lines {
+			if first == -1 && start.matches(line) {
+				first = i
+			}
+			if first != -1 && end.matches(line) {
+				last = i
+				break
+			}
+		}
+		if first == -1 {
+			continue
+		}
+		if last == -1 {
+			last = first
+		}
+
+		from := max(first-contextLines, 0)
+		to := min(last+contextLines, len(lines)-1)
+
+		var sb strings.Builder
+		for i := from; i <= to; i++ {
+			line := lines[i]
+
+			marker := " "
+			if i >= first && i <= last {
+				marker = ">"
+			}
+
+			number := ""
+			if line.NewLine != 0 {
+				number = fmt.Sprintf("%d", line.NewLine)
+			}
+
+			sb.WriteString(fmt.Sprintf("%s %5s %c%s\n", marker, number, line.Prefix, line.Text))
+		}
+		return sb.String(), nil
+	}
+
+	return "", fmt.Errorf("line %s is not part of the diff", start)
+}
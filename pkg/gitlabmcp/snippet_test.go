@@ -0,0 +1,158 @@
+package gitlabmcp
+
+import (
+	"testing"
+
+	"github.com/ondratuma/gitlab-review-mcp/pkg/gitlab"
+)
+
+// TestCodeSnippet tests the CodeSnippet function
+func TestCodeSnippet(t *testing.T) {
+	diff := "@@ -1,5 +1,6 @@\n" +
+		" package main\n" +
+		" \n" +
+		"-import \"fmt\"\n" +
+		"+import (\n" +
+		"+\t\"fmt\"\n" +
+		"+)\n" +
+		" \n"
+
+	// Test cases
+	tests := []struct {
+		name         string
+		start        LineRef
+		end          LineRef
+		contextLines int
+		expected     string
+		expectError  bool
+	}{
+		{
+			name:         "line with context",
+			start:        LineRef{NewLine: 4},
+			end:          LineRef{NewLine: 4},
+			contextLines: 1,
+			expected: "      3 +import (\n" +
+				">     4 +\t\"fmt\"\n" +
+				"      5 +)\n",
+		},
+		{
+			name:         "context clipped to the hunk",
+			start:        LineRef{OldLine: 1, NewLine: 1},
+			end:          LineRef{OldLine: 1, NewLine: 1},
+			contextLines: 2,
+			expected: ">     1  package main\n" +
+				"      2  \n" +
+				"        -import \"fmt\"\n",
+		},
+		{
+			name:         "removed line",
+			start:        LineRef{OldLine: 3},
+			end:          LineRef{OldLine: 3},
+			contextLines: 1,
+			expected: "      2  \n" +
+				">       -import \"fmt\"\n" +
+				"      3 +import (\n",
+		},
+		{
+			name:         "multi-line range",
+			start:        LineRef{OldLine: 3},
+			end:          LineRef{NewLine: 5},
+			contextLines: 0,
+			expected: ">       -import \"fmt\"\n" +
+				">     3 +import (\n" +
+				">     4 +\t\"fmt\"\n" +
+				">     5 +)\n",
+		},
+		{
+			name:         "line outside the diff",
+			start:        LineRef{NewLine: 42},
+			end:          LineRef{NewLine: 42},
+			contextLines: 1,
+			expectError:  true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			// Call the function
+			snippet, err := CodeSnippet(diff, tc.start, tc.end, tc.contextLines)
+
+			// Check the results
+			if tc.expectError {
+				if err == nil {
+					t.Errorf("expected error but got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if snippet != tc.expected {
+				t.Errorf("expected snippet:\n%s\ngot:\n%s", tc.expected, snippet)
+			}
+		})
+	}
+}
+
+// TestPositionLines tests the PositionLines function
+func TestPositionLines(t *testing.T) {
+	intPtr := func(i int) *int { return &i }
+
+	// Test cases
+	tests := []struct {
+		name          string
+		position      gitlab.NotePosition
+		expectedStart LineRef
+		expectedEnd   LineRef
+		expectedOk    bool
+	}{
+		{
+			name:          "added line",
+			position:      gitlab.NotePosition{NewLine: intPtr(10)},
+			expectedStart: LineRef{NewLine: 10},
+			expectedEnd:   LineRef{NewLine: 10},
+			expectedOk:    true,
+		},
+		{
+			name:          "removed line",
+			position:      gitlab.NotePosition{OldLine: intPtr(7)},
+			expectedStart: LineRef{OldLine: 7},
+			expectedEnd:   LineRef{OldLine: 7},
+			expectedOk:    true,
+		},
+		{
+			name: "multi-line range",
+			position: gitlab.NotePosition{
+				NewLine: intPtr(12),
+				LineRange: &gitlab.LineRange{
+					Start: &gitlab.LinePosition{Type: "old", OldLine: intPtr(9)},
+					End:   &gitlab.LinePosition{Type: "new", NewLine: intPtr(12)},
+				},
+			},
+			expectedStart: LineRef{OldLine: 9},
+			expectedEnd:   LineRef{NewLine: 12},
+			expectedOk:    true,
+		},
+		{
+			name: "image",
+			position: gitlab.NotePosition{
+				PositionType: "image",
+				X:            intPtr(10),
+				Y:            intPtr(20),
+			},
+			expectedOk: false,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			start, end, ok := PositionLines(&tc.position)
+			if ok != tc.expectedOk {
+				t.Fatalf("expected ok %t, got %t", tc.expectedOk, ok)
+			}
+			if start != tc.expectedStart || end != tc.expectedEnd {
+				t.Errorf("expected %+v-%+v, got %+v-%+v", tc.expectedStart, tc.expectedEnd, start, end)
+			}
+		})
+	}
+}
@@ -0,0 +1,250 @@
+// Package gitlabmcp provides the GitLab MCP tool functionality.
+package gitlabmcp
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"sync/atomic"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// methodCancelled is the notification a client sends to cancel a request.
+const methodCancelled = "notifications/cancelled"
+
+// stdioServer serves MCP over newline-delimited JSON-RPC on stdio. Unlike
+// server.ServeStdio it runs tool calls concurrently, so a call can be cancelled
+// by a notifications/cancelled notification while it is running.
+type stdioServer struct {
+	server *server.MCPServer
+
+	// mu guards out and inFlight
+	mu  sync.Mutex
+	out io.Writer
+	// inFlight holds the running tool calls by their compacted JSON-RPC ID
+	inFlight map[string]*inFlightCall
+
+	wg sync.WaitGroup
+}
+
+// inFlightCall is a running tool call.
+type inFlightCall struct {
+	cancel    context.CancelFunc
+	cancelled bool
+}
+
+// stdioSession is the single client session of the stdio server. Tools send
+// notifications such as progress through its channel.
+type stdioSession struct {
+	notifications chan mcp.JSONRPCNotification
+	initialized   atomic.Bool
+}
+
+// SessionID implements server.ClientSession.
+func (s *stdioSession) SessionID() string {
+	return "stdio"
+}
+
+// NotificationChannel implements server.ClientSession.
+func (s *stdioSession) NotificationChannel() chan<- mcp.JSONRPCNotification {
+	return s.notifications
+}
+
+// Initialize implements server.ClientSession.
+func (s *stdioSession) Initialize() {
+	s.initialized.Store(true)
+}
+
+// Initialized implements server.ClientSession.
+func (s *stdioSession) Initialized() bool {
+	return s.initialized.Load()
+}
+
+// stdioMessage holds the fields of a JSON-RPC message needed for dispatching.
+type stdioMessage struct {
+	ID     json.RawMessage `json:"id"`
+	Method string          `json:"method"`
+	Params struct {
+		RequestID json.RawMessage `json:"requestId"`
+	} `json:"params"`
+}
+
+// serveStdio serves s on in and out until in is closed or ctx ends. Running
+// tool calls are finished before it returns.
+func serveStdio(ctx context.Context, s *server.MCPServer, in io.Reader, out io.Writer) error {
+	stdio := &stdioServer{
+		server:   s,
+		out:      out,
+		inFlight: make(map[string]*inFlightCall),
+	}
+
+	// Like server.ServeStdio, register the only client so tools can notify it
+	session := &stdioSession{notifications: make(chan mcp.JSONRPCNotification, 100)}
+	if err := s.RegisterSession(ctx, session); err != nil {
+		return fmt.Errorf("register session: %w", err)
+	}
+	defer s.UnregisterSession(ctx, session.SessionID())
+	ctx = s.WithContext(ctx, session)
+
+	// Notifications are forwarded until the running tool calls have finished
+	stopNotifications := make(chan struct{})
+	notificationsDone := make(chan struct{})
+	go func() {
+		defer close(notificationsDone)
+		stdio.forwardNotifications(session.notifications, stopNotifications)
+	}()
+	defer func() {
+		close(stopNotifications)
+		<-notificationsDone
+	}()
+	defer stdio.wg.Wait()
+
+	lines := make(chan []byte)
+	readErr := make(chan error, 1)
+	go func() {
+		reader := bufio.NewReader(in)
+		for {
+			line, err := reader.ReadBytes('\n')
+			if len(bytes.TrimSpace(line)) > 0 {
+				select {
+				case lines <- line:
+				case <-ctx.Done():
+					return
+				}
+			}
+			if err != nil {
+				readErr <- err
+				return
+			}
+		}
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case err := <-readErr:
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return fmt.Errorf("reading input: %w", err)
+		case line := <-lines:
+			stdio.handle(ctx, line)
+		}
+	}
+}
+
+// handle dispatches a single message. Tool calls run in their own goroutine,
+// everything else in order.
+func (s *stdioServer) handle(ctx context.Context, line []byte) {
+	var message stdioMessage
+	if err := json.Unmarshal(line, &message); err != nil {
+		// The server answers with a parse error
+		s.write(s.server.HandleMessage(ctx, line))
+		return
+	}
+
+	if message.Method == methodCancelled {
+		s.cancel(message.Params.RequestID)
+		return
+	}
+
+	if message.Method != string(mcp.MethodToolsCall) || len(message.ID) == 0 {
+		s.write(s.server.HandleMessage(ctx, line))
+		return
+	}
+
+	key := requestKey(message.ID)
+	callCtx, cancel := context.WithCancel(ctx)
+	call := &inFlightCall{cancel: cancel}
+
+	s.mu.Lock()
+	s.inFlight[key] = call
+	s.mu.Unlock()
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		defer cancel()
+
+		response := s.server.HandleMessage(callCtx, line)
+
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		delete(s.inFlight, key)
+		// Cancelled requests are not answered
+		if !call.cancelled {
+			s.writeLocked(response)
+		}
+	}()
+}
+
+// forwardNotifications writes the notifications sent to the session until
+// stop is closed and the pending ones are written.
+func (s *stdioServer) forwardNotifications(notifications <-chan mcp.JSONRPCNotification, stop <-chan struct{}) {
+	for {
+		select {
+		case notification := <-notifications:
+			s.write(notification)
+		case <-stop:
+			// Flush what the finished tool calls sent
+			for {
+				select {
+				case notification := <-notifications:
+					s.write(notification)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+// cancel cancels the running tool call with the given ID. Unknown IDs are
+// ignored, as the call may have finished already.
+func (s *stdioServer) cancel(id json.RawMessage) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if call, ok := s.inFlight[requestKey(id)]; ok {
+		call.cancelled = true
+		call.cancel()
+	}
+}
+
+// write writes a message to the output.
+func (s *stdioServer) write(message mcp.JSONRPCMessage) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.writeLocked(message)
+}
+
+// writeLocked writes a message to the output, with mu held. Notifications
+// have no response, so nil messages are skipped.
+func (s *stdioServer) writeLocked(message mcp.JSONRPCMessage) {
+	if message == nil {
+		return
+	}
+
+	data, err := json.Marshal(message)
+	if err != nil {
+		return
+	}
+	_, _ = s.out.Write(append(data, '\n'))
+}
+
+// requestKey normalises a JSON-RPC ID, which may be a number or a string.
+func requestKey(id json.RawMessage) string {
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, id); err != nil {
+		return string(id)
+	}
+	return buf.String()
+}
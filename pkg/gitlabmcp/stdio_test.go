@@ -0,0 +1,176 @@
+package gitlabmcp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/ondratuma/gitlab-review-mcp/pkg/gitlab"
+)
+
+// TestServeStdioCancellation tests that notifications/cancelled cancels a running tool call
+func TestServeStdioCancellation(t *testing.T) {
+	started := make(chan struct{})
+	cancelled := make(chan struct{})
+
+	s := server.NewMCPServer("test", "0.0.1", server.WithToolCapabilities(false))
+	s.AddTool(mcp.NewTool("block"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		close(started)
+		<-ctx.Done()
+		close(cancelled)
+		return mcp.NewToolResultError(ctx.Err().Error()), nil
+	})
+	s.AddTool(mcp.NewTool("echo"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText("echo"), nil
+	})
+
+	in, input := io.Pipe()
+	output, out := io.Pipe()
+	done := make(chan error, 1)
+	go func() {
+		done <- serveStdio(context.Background(), s, in, out)
+		out.Close()
+	}()
+
+	responses := make(chan string)
+	go func() {
+		scanner := bufio.NewScanner(output)
+		for scanner.Scan() {
+			responses <- scanner.Text()
+		}
+		close(responses)
+	}()
+
+	send := func(message string) {
+		if _, err := io.WriteString(input, message+"\n"); err != nil {
+			t.Fatalf("writing input: %v", err)
+		}
+	}
+
+	// A blocking call does not hold up the next one
+	send(`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"block"}}`)
+	<-started
+	send(`{"jsonrpc":"2.0","id":"two","method":"tools/call","params":{"name":"echo"}}`)
+
+	var response struct {
+		ID any `json:"id"`
+	}
+	if err := json.Unmarshal([]byte(<-responses), &response); err != nil {
+		t.Fatalf("invalid response: %v", err)
+	}
+	if response.ID != "two" {
+		t.Errorf("expected the response to request two, got %v", response.ID)
+	}
+
+	// Cancelling the blocking call ends it without a response
+	send(`{"jsonrpc":"2.0","method":"notifications/cancelled","params":{"requestId":1,"reason":"test"}}`)
+	select {
+	case <-cancelled:
+	case <-time.After(5 * time.Second):
+		t.Fatal("tool call was not cancelled")
+	}
+
+	input.Close()
+	if err := <-done; err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	for extra := range responses {
+		t.Errorf("unexpected response to a cancelled request: %s", extra)
+	}
+}
+
+// TestServeStdioNotifications tests that notifications of tools reach the client
+func TestServeStdioNotifications(t *testing.T) {
+	s := server.NewMCPServer("test", "0.0.1", server.WithToolCapabilities(false))
+	s.AddTool(mcp.NewTool("notify"), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		if err := s.SendNotificationToClient(ctx, "notifications/progress", map[string]any{"progress": 1}); err != nil {
+			return nil, err
+		}
+		return mcp.NewToolResultText("done"), nil
+	})
+
+	input := strings.Join([]string{
+		`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-03-26","clientInfo":{"name":"test","version":"1"}}}`,
+		`{"jsonrpc":"2.0","method":"notifications/initialized"}`,
+		`{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"notify"}}`,
+	}, "\n") + "\n"
+
+	var out strings.Builder
+	if err := serveStdio(context.Background(), s, strings.NewReader(input), &out); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var methods []string
+	for _, line := range strings.Split(strings.TrimSpace(out.String()), "\n") {
+		var message struct {
+			ID     any    `json:"id"`
+			Method string `json:"method"`
+		}
+		if err := json.Unmarshal([]byte(line), &message); err != nil {
+			t.Fatalf("invalid message %q: %v", line, err)
+		}
+		if message.Method != "" {
+			methods = append(methods, message.Method)
+		}
+	}
+	if len(methods) != 1 || methods[0] != "notifications/progress" {
+		t.Errorf("expected a progress notification, got %q in:\n%s", methods, out.String())
+	}
+}
+
+// TestWithToolTimeout tests the withToolTimeout middleware
+func TestWithToolTimeout(t *testing.T) {
+	handler := withToolTimeout(10 * time.Millisecond)(func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		<-ctx.Done()
+		return mcp.NewToolResultError(ctx.Err().Error()), nil
+	})
+
+	request := mcp.CallToolRequest{}
+	request.Params.Name = "slow"
+
+	result, err := handler(context.Background(), request)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !result.IsError {
+		t.Fatal("expected an error result")
+	}
+	text := result.Content[0].(mcp.TextContent).Text
+	if !strings.Contains(text, "slow timed out after 10ms") {
+		t.Errorf("unexpected result %q", text)
+	}
+}
+
+// TestWithListCapNotes tests that capped lists are noted in the tool result
+func TestWithListCapNotes(t *testing.T) {
+	client := gitlab.NewClient("test-token")
+	client.HTTPClient = &mockHTTPClient{body: `[{"id":1},{"id":2},{"id":3}]`}
+	client.MaxListItems = 2
+
+	handler := withListCapNotes(2)(func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		notes, err := client.GetDraftNotes(ctx, "123", 7)
+		if err != nil {
+			return nil, err
+		}
+		return mcp.NewToolResultText(fmt.Sprintf("Found %d draft note(s)", len(notes))), nil
+	})
+
+	result, err := handler(context.Background(), mcp.CallToolRequest{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result.Content) != 2 {
+		t.Fatalf("expected a note after the result, got %+v", result.Content)
+	}
+	note := result.Content[1].(mcp.TextContent).Text
+	if !strings.Contains(note, "capped at 2 items") || !strings.Contains(note, "projects/123/merge_requests/7/draft_notes") {
+		t.Errorf("unexpected note %q", note)
+	}
+}
@@ -0,0 +1,216 @@
+// Package gitlabmcp provides the GitLab MCP tool functionality.
+package gitlabmcp
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/ondratuma/gitlab-review-mcp/pkg/git"
+	"github.com/ondratuma/gitlab-review-mcp/pkg/gitlab"
+)
+
+// LocalPatch replaces a range of lines of a file in the working tree.
+type LocalPatch struct {
+	Path      string
+	StartLine int
+	EndLine   int
+	NewLines  []string
+}
+
+// PlanLocalSuggestion maps the lines start to end of path as of the reviewed
+// commit onto the working tree. localDiff is the diff between that commit and
+// the working tree. It fails when any of the lines changed since the review.
+func PlanLocalSuggestion(localDiff, path string, start, end int, suggestion gitlab.SuggestionBlock) (LocalPatch, error) {
+	patch := LocalPatch{NewLines: suggestion.Lines}
+
+	for line := start; line <= end; line++ {
+		mapping, err := git.MapLine(localDiff, path, line)
+		if err != nil {
+			return patch, err
+		}
+
+		// The range must still be there, unchanged and in one piece
+		if mapping.Deleted || (line > start && mapping.Line != patch.EndLine+1) {
+			return patch, fmt.Errorf("conflict: lines %d-%d of %s changed since the review", start, end, path)
+		}
+
+		if line == start {
+			patch.Path = mapping.Path
+			patch.StartLine = mapping.Line
+		}
+		patch.EndLine = mapping.Line
+	}
+
+	return patch, nil
+}
+
+// Apply returns content with the patch applied, along with the replaced lines.
+func (p LocalPatch) Apply(content string) (string, []string, error) {
+	trailingNewline := strings.HasSuffix(content, "\n")
+	lines := strings.Split(strings.TrimSuffix(content, "\n"), "\n")
+
+	if p.StartLine < 1 || p.EndLine > len(lines) || p.StartLine > p.EndLine {
+		return "", nil, fmt.Errorf("lines %d-%d are out of range for %s with %d lines", p.StartLine, p.EndLine, p.Path, len(lines))
+	}
+
+	oldLines := slices.Clone(lines[p.StartLine-1 : p.EndLine])
+
+	patched := make([]string, 0, len(lines)-len(oldLines)+len(p.NewLines))
+	patched = append(patched, lines[:p.StartLine-1]...)
+	patched = append(patched, p.NewLines...)
+	patched = append(patched, lines[p.EndLine:]...)
+
+	result := strings.Join(patched, "\n")
+	if trailingNewline && len(patched) > 0 {
+		result += "\n"
+	}
+
+	return result, oldLines, nil
+}
+
+// Diff formats the patch as a unified diff hunk given the replaced lines.
+func (p LocalPatch) Diff(oldLines []string) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("--- a/%s\n+++ b/%s\n", p.Path, p.Path))
+	sb.WriteString(fmt.Sprintf("@@ -%d,%d +%d,%d @@\n", p.StartLine, len(oldLines), p.StartLine, len(p.NewLines)))
+	for _, line := range oldLines {
+		sb.WriteString("-" + line + "\n")
+	}
+	for _, line := range p.NewLines {
+		sb.WriteString("+" + line + "\n")
+	}
+	return sb.String()
+}
+
+// findSuggestionNote returns the note of the discussion holding suggestions,
+// or the note with the given ID when noteID is not 0.
+func findSuggestionNote(discussion *gitlab.Discussion, noteID int) (*gitlab.MergeRequestNote, error) {
+	for i := range discussion.Notes {
+		note := &discussion.Notes[i]
+		if noteID != 0 && note.ID != noteID {
+			continue
+		}
+		if len(note.SuggestionBlocks()) > 0 {
+			return note, nil
+		}
+		if noteID != 0 {
+			return nil, fmt.Errorf("note %d has no suggestions", noteID)
+		}
+	}
+
+	if noteID != 0 {
+		return nil, fmt.Errorf("note %d is not part of discussion %s", noteID, discussion.ID)
+	}
+	return nil, fmt.Errorf("discussion %s has no suggestions", discussion.ID)
+}
+
+// ApplySuggestionHandler handles the applySuggestion tool request.
+func ApplySuggestionHandler(ctx context.Context, request mcp.CallToolRequest, client *gitlab.Client, config Config) (*mcp.CallToolResult, error) {
+	discussionID, err := request.RequireString("discussionID")
+	if err != nil {
+		return toolError(err), nil
+	}
+
+	noteID := request.GetInt("noteID", 0)
+	index := request.GetInt("suggestionIndex", 0)
+	dryRun := request.GetBool("dryRun", false)
+
+	mr, err := ResolveMergeRequest(ctx, request, client, config)
+	if err != nil {
+		return toolError(err), nil
+	}
+
+	discussion, err := client.GetMergeRequestDiscussion(ctx, mr.ProjectID, mr.IID, discussionID)
+	if err != nil {
+		return toolError(err), nil
+	}
+
+	note, err := findSuggestionNote(discussion, noteID)
+	if err != nil {
+		return toolError(err), nil
+	}
+
+	suggestions := note.SuggestionBlocks()
+	if index < 0 || index >= len(suggestions) {
+		return mcp.NewToolResultError(fmt.Sprintf("Note %d has %d suggestion(s), index %d is out of range", note.ID, len(suggestions), index)), nil
+	}
+
+	// Suggestions are relative to the line the thread is anchored to
+	position := discussion.Notes[0].Position
+	if position == nil || position.NewLine == nil || position.HeadSHA == "" {
+		return mcp.NewToolResultError(fmt.Sprintf("Discussion %s is not anchored to a line of the new file", discussionID)), nil
+	}
+	start, end := suggestions[index].LineRange(*position.NewLine)
+
+	dir := repoPath(request, config)
+	localDiff, err := git.DiffWorkingTree(dir, position.HeadSHA)
+	if err != nil {
+		return toolError(err), nil
+	}
+
+	patch, err := PlanLocalSuggestion(localDiff, position.FilePath(), start, end, suggestions[index])
+	if err != nil {
+		return toolError(err), nil
+	}
+
+	root, err := git.GetRepositoryRoot(dir)
+	if err != nil {
+		return toolError(err), nil
+	}
+	filePath := filepath.Join(root, filepath.FromSlash(patch.Path))
+
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return toolError(err), nil
+	}
+
+	patched, oldLines, err := patch.Apply(string(content))
+	if err != nil {
+		return toolError(err), nil
+	}
+
+	if dryRun {
+		return mcp.NewToolResultText(fmt.Sprintf("Dry run, %s is unchanged:\n%s", patch.Path, patch.Diff(oldLines))), nil
+	}
+
+	info, err := os.Stat(filePath)
+	if err != nil {
+		return toolError(err), nil
+	}
+	if err := os.WriteFile(filePath, []byte(patched), info.Mode().Perm()); err != nil {
+		return toolError(err), nil
+	}
+
+	return mcp.NewToolResultText(fmt.Sprintf("Applied suggestion to %s:\n%s", patch.Path, patch.Diff(oldLines))), nil
+}
+
+// ApplyRemoteSuggestionsHandler handles the applyRemoteSuggestions tool request.
+func ApplyRemoteSuggestionsHandler(ctx context.Context, request mcp.CallToolRequest, client *gitlab.Client, config Config) (*mcp.CallToolResult, error) {
+	suggestionIDs, err := request.RequireIntSlice("suggestionIDs")
+	if err != nil {
+		return toolError(err), nil
+	}
+	if len(suggestionIDs) == 0 {
+		return mcp.NewToolResultError("At least one suggestion ID is required"), nil
+	}
+
+	commitMessage := request.GetString("commitMessage", "")
+
+	// A single suggestion has its own endpoint, several are committed together
+	if len(suggestionIDs) == 1 {
+		if _, err := client.ApplySuggestion(ctx, suggestionIDs[0], commitMessage); err != nil {
+			return toolError(err), nil
+		}
+	} else {
+		if _, err := client.ApplySuggestions(ctx, suggestionIDs, commitMessage); err != nil {
+			return toolError(err), nil
+		}
+	}
+
+	return mcp.NewToolResultText(fmt.Sprintf("Applied %d suggestion(s) on GitLab; pull the source branch to get the commit", len(suggestionIDs))), nil
+}
@@ -0,0 +1,97 @@
+package gitlabmcp
+
+import (
+	"testing"
+
+	"github.com/ondratuma/gitlab-review-mcp/pkg/gitlab"
+)
+
+// TestPlanLocalSuggestion tests the PlanLocalSuggestion function
+func TestPlanLocalSuggestion(t *testing.T) {
+	// Two lines were inserted at the top and line 10 was rewritten since the review
+	localDiff := "diff --git a/main.go b/main.go\n" +
+		"--- a/main.go\n" +
+		"+++ b/main.go\n" +
+		"@@ -1,0 +2,2 @@\n" +
+		"+// one\n" +
+		"+// two\n" +
+		"@@ -10 +12 @@\n" +
+		"-a()\n" +
+		"+b()\n"
+
+	suggestion := gitlab.SuggestionBlock{Lines: []string{"x()"}}
+
+	// Test cases
+	tests := []struct {
+		name          string
+		start         int
+		end           int
+		expectedStart int
+		expectedEnd   int
+		expectError   bool
+	}{
+		{
+			name:          "shifted lines",
+			start:         5,
+			end:           6,
+			expectedStart: 7,
+			expectedEnd:   8,
+		},
+		{
+			name:        "changed line",
+			start:       9,
+			end:         10,
+			expectError: true,
+		},
+		{
+			name:        "insertion inside the range",
+			start:       1,
+			end:         2,
+			expectError: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			patch, err := PlanLocalSuggestion(localDiff, "main.go", tc.start, tc.end, suggestion)
+			if tc.expectError {
+				if err == nil {
+					t.Errorf("expected error but got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if patch.Path != "main.go" || patch.StartLine != tc.expectedStart || patch.EndLine != tc.expectedEnd {
+				t.Errorf("expected main.go:%d-%d, got %s:%d-%d", tc.expectedStart, tc.expectedEnd, patch.Path, patch.StartLine, patch.EndLine)
+			}
+		})
+	}
+}
+
+// TestLocalPatchApply tests the LocalPatch.Apply method
+func TestLocalPatchApply(t *testing.T) {
+	patch := LocalPatch{Path: "main.go", StartLine: 2, EndLine: 3, NewLines: []string{"B"}}
+
+	patched, oldLines, err := patch.Apply("a\nb\nc\nd\n")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if patched != "a\nB\nd\n" {
+		t.Errorf("unexpected patched content %q", patched)
+	}
+	if len(oldLines) != 2 || oldLines[0] != "b" || oldLines[1] != "c" {
+		t.Errorf("unexpected replaced lines %q", oldLines)
+	}
+
+	expectedDiff := "--- a/main.go\n+++ b/main.go\n@@ -2,2 +2,1 @@\n-b\n-c\n+B\n"
+	if diff := patch.Diff(oldLines); diff != expectedDiff {
+		t.Errorf("expected diff %q, got %q", expectedDiff, diff)
+	}
+
+	// Lines past the end of the file are a conflict too
+	if _, _, err := (LocalPatch{Path: "main.go", StartLine: 4, EndLine: 6}).Apply("a\nb\n"); err == nil {
+		t.Errorf("expected error but got nil")
+	}
+}